@@ -0,0 +1,156 @@
+// Package apperror defines the errors services return to controllers. Each
+// error has a code that picks the HTTP status, a message that is safe to show
+// to clients, and optionally the internal cause, which is only logged.
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Code classifies an error. Codes are errors themselves so callers can test
+// the kind with errors.Is(err, apperror.NotFound).
+type Code string
+
+const (
+	BadRequest      Code = "BAD_REQUEST"
+	Validation      Code = "VALIDATION_FAILED"
+	Unauthorized    Code = "UNAUTHORIZED"
+	Forbidden       Code = "FORBIDDEN"
+	NotFound        Code = "NOT_FOUND"
+	Conflict        Code = "CONFLICT"
+	TooManyRequests Code = "TOO_MANY_REQUESTS"
+	Timeout         Code = "TIMEOUT"
+	Internal        Code = "INTERNAL_ERROR"
+)
+
+var statuses = map[Code]int{
+	BadRequest:      http.StatusBadRequest,
+	Validation:      http.StatusBadRequest,
+	Unauthorized:    http.StatusUnauthorized,
+	Forbidden:       http.StatusForbidden,
+	NotFound:        http.StatusNotFound,
+	Conflict:        http.StatusConflict,
+	TooManyRequests: http.StatusTooManyRequests,
+	Timeout:         http.StatusServiceUnavailable,
+	Internal:        http.StatusInternalServerError,
+}
+
+func (c Code) Error() string {
+	return string(c)
+}
+
+// Status is the HTTP status for the code.
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// New returns an error of this code with a public message.
+func (c Code) New(message string) *Error {
+	return &Error{Code: c, Message: message}
+}
+
+// Wrap returns an error of this code with a public message and an internal cause.
+func (c Code) Wrap(err error, message string) *Error {
+	return &Error{Code: c, Message: message, Err: err}
+}
+
+// Error is an application error. Message and Details go to the client; Err
+// is the internal cause and never leaves the server.
+type Error struct {
+	Code    Code
+	Message string
+	Details interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the error's code, so errors.Is(err, apperror.Conflict) works
+// through any wrapping.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(Code)
+	return ok && code == e.Code
+}
+
+// Status is the HTTP status for the error's code.
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
+
+// WithDetails attaches client-facing details, e.g. the fields that failed.
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// Database error codes we map to client errors.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	// statement_timeout or a cancelled query
+	pgQueryCanceled = "57014"
+	// max_execution_time exceeded
+	mysqlQueryInterrupted = 3024
+)
+
+// From turns any error into an *Error. Application errors pass through,
+// known database errors become client errors and everything else is an
+// internal error whose cause is hidden from clients.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var pgErr *pgconn.PgError
+	var mysqlErr *mysql.MySQLError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound.Wrap(err, "Resource not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return Conflict.Wrap(err, "Resource already exists")
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return Conflict.Wrap(err, "Resource is still referenced or refers to a missing resource")
+	case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		return Conflict.Wrap(err, "Resource already exists")
+	case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+		return Conflict.Wrap(err, "Resource is still referenced or refers to a missing resource")
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled,
+		errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlQueryInterrupted:
+		return Timeout.Wrap(err, "Request timed out")
+	case errors.Is(err, context.Canceled):
+		return Timeout.Wrap(err, "Request was cancelled")
+	}
+
+	return Internal.Wrap(err, "Internal server error")
+}
+
+// Bind wraps a request binding error. The binding message is kept as the
+// details since it names the offending field.
+func Bind(err error) *Error {
+	return Validation.Wrap(err, "Validation failed").WithDetails(err.Error())
+}
@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Get when the key is absent or expired.
+var ErrMiss = errors.New("cache miss")
+
+// Store is a byte-oriented key/value cache with per-entry TTL. Values are
+// serialized by the caller so the same data can live in process or in Redis.
+type Store interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(keys ...string) error
+	// DeletePrefix removes every key starting with prefix.
+	DeletePrefix(prefix string) error
+}
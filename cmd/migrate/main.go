@@ -0,0 +1,132 @@
+// Command migrate applies and reverts the versioned database migrations.
+//
+//	go run ./cmd/migrate up [N]        apply pending migrations, or the next N
+//	go run ./cmd/migrate down [N]      revert the last migration, or the last N
+//	go run ./cmd/migrate redo          revert and re-apply the last migration
+//	go run ./cmd/migrate status        list migrations and when they were applied
+//	go run ./cmd/migrate create NAME   add migrations/NNNN_NAME.{up,down}.sql
+//
+// -dry-run prints the SQL of up, down and redo without keeping any change
+// (Postgres and SQLite only, as MySQL commits schema changes at once);
+// create -go writes a Go migration instead of SQL files. The database is
+// configured as for the server (-config, .env, environment).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"golang-backend/config"
+	"golang-backend/migrations"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Print the SQL of up, down or redo and roll it back")
+	goFile := flag.Bool("go", false, "create: write a Go migration instead of SQL files")
+	dir := flag.String("dir", "migrations", "create: directory of the migrations package")
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: migrate [flags] up [N] | down [N] | redo | status | create NAME")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "create" {
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		paths, err := migrations.Create(*dir, flag.Arg(1), *goFile)
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		return
+	}
+
+	count, err := countArg(command)
+	if err != nil {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	db, err := config.OpenMigrationDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+
+	migrator := migrations.NewMigrator(db)
+	migrator.DryRun = *dryRun
+
+	switch command {
+	case "up":
+		var applied []migrations.Migration
+		applied, err = migrator.Up(count)
+		if err == nil && len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		_, err = migrator.Down(max(count, 1))
+	case "redo":
+		err = migrator.Redo()
+	case "status":
+		err = printStatus(migrator)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
+
+// countArg validates the command and reads the optional N of up and down.
+func countArg(command string) (int, error) {
+	switch command {
+	case "up", "down":
+		if flag.NArg() == 1 {
+			return 0, nil
+		}
+		if flag.NArg() == 2 {
+			n, err := strconv.Atoi(flag.Arg(1))
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("N must be a positive number")
+			}
+			return n, nil
+		}
+	case "redo", "status":
+		if flag.NArg() == 1 {
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown command")
+}
+
+func printStatus(migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.AppliedAt != nil {
+			state, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if s.Missing {
+			state = "applied, missing from this build"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
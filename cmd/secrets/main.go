@@ -0,0 +1,256 @@
+// Command secrets manages the encrypted secrets file read by the server.
+//
+//	go run ./cmd/secrets keygen          print a new master key
+//	go run ./cmd/secrets edit            decrypt into $EDITOR and encrypt on save
+//	go run ./cmd/secrets set NAME        set NAME to the value read from stdin
+//	go run ./cmd/secrets list            list the names stored, without values
+//
+// The file is -file, $SECRETS_FILE or secrets.enc; the master key comes from
+// SECRETS_KEY or SECRETS_KEY_FILE.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang-backend/config"
+	"golang-backend/secrets"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func main() {
+	file := flag.String("file", "", "Path to the secrets file (default $SECRETS_FILE or secrets.enc)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: secrets [-file path] keygen | edit | set NAME | list")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	path := *file
+	if path == "" {
+		path = os.Getenv("SECRETS_FILE")
+	}
+	if path == "" {
+		path = "secrets.enc"
+	}
+
+	var err error
+	switch flag.Arg(0) {
+	case "keygen":
+		err = keygen()
+	case "edit":
+		err = edit(path)
+	case "set":
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		err = set(path, flag.Arg(1), os.Stdin)
+	case "list":
+		err = list(path)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "secrets:", err)
+		os.Exit(1)
+	}
+}
+
+func keygen() error {
+	key, err := secrets.GenerateKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println(key)
+	return nil
+}
+
+// edit decrypts the file into a private temporary file, opens it in
+// $EDITOR and encrypts the result. A new file starts from a template listing
+// the secret settings.
+func edit(path string) error {
+	key, plaintext, err := read(path)
+	if err != nil {
+		return err
+	}
+	if plaintext == nil {
+		plaintext = template()
+	}
+
+	dir, err := os.MkdirTemp("", "secrets-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "secrets.yaml")
+	if err := os.WriteFile(tmp, plaintext, 0o600); err != nil {
+		return err
+	}
+
+	for {
+		if err := runEditor(tmp); err != nil {
+			return err
+		}
+		edited, err := os.ReadFile(tmp)
+		if err != nil {
+			return err
+		}
+		if err = check(edited); err == nil {
+			if err := secrets.WriteFile(path, key, edited); err != nil {
+				return err
+			}
+			fmt.Println("Saved", path)
+			return nil
+		}
+
+		fmt.Fprintln(os.Stderr, err)
+		if !confirm("Edit again? Answering no discards the changes") {
+			return errors.New("changes discarded")
+		}
+	}
+}
+
+func set(path, name string, in io.Reader) error {
+	if !config.KnownEnv(name) {
+		return fmt.Errorf("unknown setting %s", name)
+	}
+
+	key, plaintext, err := read(path)
+	if err != nil {
+		return err
+	}
+
+	value, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(plaintext, &doc); err != nil {
+		return err
+	}
+	setKey(&doc, name, strings.TrimRight(string(value), "\r\n"))
+
+	updated, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+	return secrets.WriteFile(path, key, updated)
+}
+
+func list(path string) error {
+	_, plaintext, err := read(path)
+	if err != nil {
+		return err
+	}
+	values, err := secrets.Parse(plaintext)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// read loads the master key and decrypts the file; plaintext is nil when
+// the file does not exist yet.
+func read(path string) ([]byte, []byte, error) {
+	key, err := config.MasterKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return key, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plaintext, err := secrets.Open(key, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, plaintext, nil
+}
+
+// check rejects content the server would refuse to start with.
+func check(plaintext []byte) error {
+	values, err := secrets.Parse(plaintext)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for name := range values {
+		if !config.KnownEnv(name) {
+			errs = append(errs, fmt.Errorf("unknown setting %s", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func template() []byte {
+	var b strings.Builder
+	b.WriteString("# Secrets as NAME: value, using the environment variable names.\n")
+	for _, name := range config.SecretEnv() {
+		fmt.Fprintf(&b, "# %s: \n", name)
+	}
+	return []byte(b.String())
+}
+
+// setKey sets name in the top-level mapping of doc, keeping comments and
+// the order of the other entries.
+func setKey(doc *yaml.Node, name, value string) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		*doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	mapping := doc.Content[0]
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			mapping.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+func runEditor(path string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(answer), "y")
+}
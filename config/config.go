@@ -2,84 +2,273 @@ package config
 
 import (
 	"fmt"
-	"log"
-	"os"
-	"strconv"
+	"log/slog"
+	"net/url"
+	"strings"
 	"time"
-
-	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// Config holds the settings of every subsystem. Values are layered: Default,
+// then the YAML file, then environment variables (.env included), then
+// command line flags. Each field names its YAML key and environment
+// variable; fields tagged secret are redacted when the config is printed,
+// and fields tagged reload take effect on Live.Reload without a restart.
 type Config struct {
-	SMTPHost string
-	SMTPPort int
-	SMTPUser string
-	SMTPPass string
+	Server         ServerConfig         `yaml:"server"`
+	Log            LogConfig            `yaml:"log"`
+	Database       DatabaseConfig       `yaml:"database"`
+	JWT            JWTConfig            `yaml:"jwt"`
+	SMTP           SMTPConfig           `yaml:"smtp"`
+	CORS           CORSConfig           `yaml:"cors"`
+	RateLimit      RateLimitConfig      `yaml:"rate_limit"`
+	RBAC           RBACConfig           `yaml:"rbac"`
+	PrincipalCache PrincipalCacheConfig `yaml:"principal_cache"`
+	Redis          RedisConfig          `yaml:"redis"`
+	Tenant         TenantConfig         `yaml:"tenant"`
+	Locale         LocaleConfig         `yaml:"locale"`
+	Features       FeatureFlags         `yaml:"features"`
 }
 
-var AppConfig *Config
-var DB *gorm.DB
+type ServerConfig struct {
+	// development, staging or production
+	Environment string `yaml:"environment" env:"ENVIRONMENT"`
+	Port        int    `yaml:"port" env:"PORT"`
+	// How often the config files are checked for changes; 0 reloads on
+	// SIGHUP only
+	ConfigWatchInterval time.Duration `yaml:"config_watch_interval" env:"CONFIG_WATCH_INTERVAL"`
+	// RequestTimeout is the deadline of each request's context, which
+	// cancels its database work; 0 disables
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT" reload:"true"`
+	// MetricsAddr is the host:port /metrics listens on, apart from the API
+	// so the public port never serves it; empty disables the endpoint
+	MetricsAddr string `yaml:"metrics_addr" env:"METRICS_ADDR"`
+}
 
-func LoadEnv() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
-	}
+// Production reports whether the stricter production checks apply.
+func (s ServerConfig) Production() bool {
+	return s.Environment == "production"
+}
 
-	AppConfig = &Config{
-		SMTPHost: getEnv("SMTP_HOST", "localhost"),
-		SMTPPort: getEnvAsInt("SMTP_PORT", 1025),
-		SMTPUser: getEnv("SMTP_USER", ""),
-		SMTPPass: getEnv("SMTP_PASS", ""),
-	}
+// Addr is the listen address for the HTTP server.
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
 }
 
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+type LogConfig struct {
+	// debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL" reload:"true"`
+	// File is rotated by size; empty logs to stdout only
+	File string `yaml:"file" env:"LOG_FILE"`
+}
+
+// SlogLevel converts Level, which Validate has already checked.
+func (l LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(l.Level))
+	return level
+}
+
+type DatabaseConfig struct {
+	// Driver is postgres, mysql or sqlite. For sqlite, Name is the path of
+	// the database file and the connection settings are ignored.
+	Driver          string        `yaml:"driver" env:"DB_TYPE"`
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"ssl_mode" env:"DB_SSLMODE"`
+	TimeZone        string        `yaml:"time_zone" env:"DB_TIMEZONE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	// StatementTimeout makes the server cancel longer statements (Postgres
+	// statement_timeout, MySQL max_execution_time for SELECT); 0 disables
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// LogLevel of SQL logging: silent, error, warn (adds slow queries) or
+	// info (every statement)
+	LogLevel           string        `yaml:"log_level" env:"DB_LOG_LEVEL"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+	// LogParams logs statement parameters instead of placeholders
+	LogParams bool `yaml:"log_params" env:"DB_LOG_PARAMS"`
+	// Replicas are DSNs of read replicas, in the format of Driver, that
+	// serve list and search queries; empty sends every query to the primary
+	Replicas []string `yaml:"replicas" env:"DB_REPLICAS" secret:"true"`
+	// ReplicaHealthInterval is how often replicas are pinged; failing ones
+	// get no reads until they answer again
+	ReplicaHealthInterval time.Duration `yaml:"replica_health_interval" env:"DB_REPLICA_HEALTH_INTERVAL"`
+	// ReadYourWrites is how long a user's reads stay on the primary after
+	// they change data, so replication lag never hides their own change
+	ReadYourWrites time.Duration `yaml:"read_your_writes" env:"DB_READ_YOUR_WRITES"`
+}
+
+// DSN is the connection string in the format of the driver.
+func (d DatabaseConfig) DSN() string {
+	switch d.Driver {
+	case "mysql":
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=%s",
+			d.User, d.Password, d.Host, d.Port, d.Name, url.QueryEscape(d.TimeZone))
+		if d.StatementTimeout > 0 {
+			dsn += fmt.Sprintf("&max_execution_time=%d", d.StatementTimeout.Milliseconds())
+		}
+		return dsn
+	case "sqlite":
+		separator := "?"
+		if strings.Contains(d.Name, "?") {
+			separator = "&"
+		}
+		return d.Name + separator + "_foreign_keys=on&_busy_timeout=5000"
+	default:
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+			d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode, d.TimeZone)
+		if d.StatementTimeout > 0 {
+			dsn += fmt.Sprintf(" statement_timeout=%d", d.StatementTimeout.Milliseconds())
+		}
+		return dsn
 	}
-	return fallback
 }
 
-func getEnvAsInt(key string, fallback int) int {
-	valueStr := getEnv(key, "")
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
+// MigrationDSN is DSN for the migrator. On MySQL it allows several
+// statements per query, which SQL migration files need; the application
+// pool never does, so a statement cannot smuggle in a second one.
+func (d DatabaseConfig) MigrationDSN() string {
+	if d.Driver == "mysql" {
+		return d.DSN() + "&multiStatements=true"
 	}
-	return fallback
-}
-
-func InitDB() *gorm.DB {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_SSLMODE"),
-		os.Getenv("DB_TIMEZONE"),
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	return d.DSN()
+}
+
+type JWTConfig struct {
+	Secret          string `yaml:"secret" env:"JWT_SECRET" secret:"true"`
+	ExpirationHours int    `yaml:"expiration_hours" env:"JWT_EXPIRATION_HOURS"`
+}
+
+// Expiration is how long issued tokens stay valid.
+func (j JWTConfig) Expiration() time.Duration {
+	return time.Duration(j.ExpirationHours) * time.Hour
+}
+
+type SMTPConfig struct {
+	Host string `yaml:"host" env:"SMTP_HOST"`
+	Port int    `yaml:"port" env:"SMTP_PORT"`
+	User string `yaml:"user" env:"SMTP_USER"`
+	// SMTP_PASS is the old name, still read when SMTP_PASSWORD is unset
+	Password string `yaml:"password" env:"SMTP_PASSWORD,SMTP_PASS" secret:"true"`
+	// From defaults to User
+	From string `yaml:"from" env:"SMTP_FROM"`
+}
+
+// Sender is the From address of outgoing email.
+func (s SMTPConfig) Sender() string {
+	if s.From != "" {
+		return s.From
 	}
+	return s.User
+}
+
+type CORSConfig struct {
+	// Listed origins may send credentials; "*" lets any other origin call
+	// the API without them
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" reload:"true"`
+}
 
-	sqlDB, err := db.DB()
-	if err != nil {
-		log.Fatalf("Failed to get database instance: %v", err)
+// Allows reports whether a browser origin may call the API, and whether it
+// is listed by name and so may send cookies and Authorization headers.
+func (c CORSConfig) Allows(origin string) (allowed, credentials bool) {
+	for _, listed := range c.AllowedOrigins {
+		if strings.EqualFold(listed, origin) {
+			return true, true
+		}
+		if listed == "*" {
+			allowed = true
+		}
 	}
+	return allowed, false
+}
+
+type RateLimitConfig struct {
+	// Requests per second and burst, per client IP
+	IPRate  float64 `yaml:"ip_rate" env:"RATE_LIMIT_IP_RPS" reload:"true"`
+	IPBurst int     `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST" reload:"true"`
+	// Requests per second and burst, per authenticated user
+	UserRate  float64 `yaml:"user_rate" env:"RATE_LIMIT_USER_RPS" reload:"true"`
+	UserBurst int     `yaml:"user_burst" env:"RATE_LIMIT_USER_BURST" reload:"true"`
+}
+
+type RBACConfig struct {
+	// Background sweep of time-bound role assignments
+	RoleSweepInterval time.Duration `yaml:"role_sweep_interval" env:"ROLE_SWEEP_INTERVAL"`
+	RoleExpiryNotice  time.Duration `yaml:"role_expiry_notice" env:"ROLE_EXPIRY_NOTICE"`
+	// Log every policy decision, for debugging authorization
+	PolicyDecisionLog bool `yaml:"policy_decision_log" env:"POLICY_DECISION_LOG" reload:"true"`
+	// DefaultRole is granted to every user who registers; empty grants none
+	DefaultRole string `yaml:"default_role" env:"DEFAULT_ROLE" reload:"true"`
+}
+
+type PrincipalCacheConfig struct {
+	// memory, redis or none
+	Backend string        `yaml:"backend" env:"PRINCIPAL_CACHE_BACKEND"`
+	TTL     time.Duration `yaml:"ttl" env:"PRINCIPAL_CACHE_TTL"`
+	Size    int           `yaml:"size" env:"PRINCIPAL_CACHE_SIZE"`
+}
 
-	// Performance optimization for high concurrency
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+type RedisConfig struct {
+	Addr     string `yaml:"addr" env:"REDIS_ADDR"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+}
 
-	log.Println("✓ Database connected successfully")
-	DB = db
-	return db
+type TenantConfig struct {
+	// Requests to <slug>.BaseDomain target that organization
+	BaseDomain string `yaml:"base_domain" env:"TENANT_BASE_DOMAIN"`
+}
+
+type LocaleConfig struct {
+	// Fallback language of API messages and emails
+	Default string `yaml:"default" env:"DEFAULT_LOCALE"`
+}
+
+type FeatureFlags struct {
+	// New users must verify their email before logging in
+	EmailVerification bool `yaml:"email_verification" env:"ENABLE_EMAIL_VERIFICATION" reload:"true"`
+	// Users may enable TOTP two-factor authentication
+	TwoFactorAuth bool `yaml:"two_factor_auth" env:"ENABLE_TWO_FACTOR_AUTH" reload:"true"`
+}
+
+// Default is the configuration before any source is applied, suitable for
+// development against a local Postgres.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{Environment: "development", Port: 8080, ConfigWatchInterval: 5 * time.Second, RequestTimeout: 30 * time.Second, MetricsAddr: "127.0.0.1:9090"},
+		Log:    LogConfig{Level: "info", File: "logs/server.log"},
+		Database: DatabaseConfig{
+			Driver:                "postgres",
+			Host:                  "localhost",
+			Port:                  5432,
+			User:                  "postgres",
+			Name:                  "mydb",
+			SSLMode:               "disable",
+			TimeZone:              "UTC",
+			MaxOpenConns:          100,
+			MaxIdleConns:          10,
+			ConnMaxLifetime:       time.Hour,
+			StatementTimeout:      30 * time.Second,
+			LogLevel:              "warn",
+			SlowQueryThreshold:    200 * time.Millisecond,
+			ReplicaHealthInterval: 10 * time.Second,
+			ReadYourWrites:        5 * time.Second,
+		},
+		JWT:       JWTConfig{ExpirationHours: 24},
+		SMTP:      SMTPConfig{Host: "localhost", Port: 1025},
+		RateLimit: RateLimitConfig{IPRate: 5, IPBurst: 10, UserRate: 10, UserBurst: 15},
+		RBAC: RBACConfig{
+			RoleSweepInterval: time.Minute,
+			RoleExpiryNotice:  24 * time.Hour,
+			DefaultRole:       "user",
+		},
+		PrincipalCache: PrincipalCacheConfig{Backend: "memory", TTL: time.Minute, Size: 10000},
+		Redis:          RedisConfig{Addr: "localhost:6379"},
+		Locale:         LocaleConfig{Default: "en"},
+		Features:       FeatureFlags{EmailVerification: true, TwoFactorAuth: true},
+	}
 }
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"log"
+
+	"golang-backend/entity"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// OpenDatabase connects to the database and applies the pool settings.
+func OpenDatabase(cfg DatabaseConfig) (*gorm.DB, error) {
+	return openDatabase(cfg, cfg.DSN())
+}
+
+// OpenMigrationDatabase connects like OpenDatabase with MigrationDSN, for
+// running migrations only. Close it once they are done.
+func OpenMigrationDatabase(cfg DatabaseConfig) (*gorm.DB, error) {
+	return openDatabase(cfg, cfg.MigrationDSN())
+}
+
+func openDatabase(cfg DatabaseConfig, dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector(cfg.Driver, dsn), &gorm.Config{
+		Logger:         newSQLLogger(cfg),
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+
+	if err := entity.SetupJoinTables(db); err != nil {
+		return nil, fmt.Errorf("set up join tables: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get database instance: %w", err)
+	}
+
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	log.Println("✓ Database connected successfully")
+	return db, nil
+}
+
+// dialector picks the gorm driver for driver. The SQLite driver needs cgo;
+// a binary built with CGO_ENABLED=0 fails to connect.
+func dialector(driver, dsn string) gorm.Dialector {
+	switch driver {
+	case "mysql":
+		// Microseconds, as Postgres keeps them; audit hashes depend on it
+		precision := 6
+		return mysql.New(mysql.Config{DSN: dsn, DefaultDatetimePrecision: &precision})
+	case "sqlite":
+		return sqlite.Open(dsn)
+	default:
+		return postgres.Open(dsn)
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Reload triggers recorded in ReloadResult.
+const (
+	ReloadSignal = "signal"
+	ReloadFile   = "file"
+)
+
+// ReloadResult describes one reload attempt. Applied lists the settings
+// that changed; RestartRequired lists changed settings that are not tagged
+// reload and keep their old value until the next restart.
+type ReloadResult struct {
+	At              time.Time `json:"at"`
+	Trigger         string    `json:"trigger"`
+	Success         bool      `json:"success"`
+	Error           string    `json:"error,omitempty"`
+	Applied         []string  `json:"applied,omitempty"`
+	RestartRequired []string  `json:"restart_required,omitempty"`
+}
+
+// ReloadStatus is the active configuration version, counted from 1 at
+// startup, and the outcome of the most recent reload.
+type ReloadStatus struct {
+	Version    int           `json:"version"`
+	ActiveAt   time.Time     `json:"active_at"`
+	LastReload *ReloadResult `json:"last_reload,omitempty"`
+}
+
+// Live holds the configuration in effect. Readers call Get on every use and
+// never keep the result, so a reload is seen by the next request.
+type Live struct {
+	loader    *Loader
+	current   atomic.Pointer[Config]
+	mu        sync.Mutex // serializes reloads and guards the fields below
+	status    ReloadStatus
+	listeners []func(previous, current *Config)
+}
+
+// NewLive starts at version 1 with cfg. loader reads the sources again on
+// Reload; it may be nil when the configuration never reloads, as in tests.
+func NewLive(cfg *Config, loader *Loader) *Live {
+	l := &Live{loader: loader, status: ReloadStatus{Version: 1, ActiveAt: time.Now()}}
+	l.current.Store(cfg)
+	return l
+}
+
+// Get returns the configuration in effect. It must not be modified.
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// OnChange registers fn to run after a reload changes any setting.
+func (l *Live) OnChange(fn func(previous, current *Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+// Status reports the active version and the last reload.
+func (l *Live) Status() ReloadStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	status := l.status
+	if status.LastReload != nil {
+		last := *status.LastReload
+		status.LastReload = &last
+	}
+	return status
+}
+
+// Reload reads every source again and swaps in the reloadable settings.
+// An invalid configuration is rejected as a whole and the current one stays
+// in effect.
+func (l *Live) Reload(trigger string) ReloadResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := ReloadResult{At: time.Now(), Trigger: trigger}
+	loaded, err := l.load()
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Success = true
+		l.apply(loaded, &result)
+	}
+
+	l.status.LastReload = &result
+	if result.Success {
+		slog.Info("Configuration reloaded", "trigger", trigger, "version", l.status.Version,
+			"applied", result.Applied, "restart_required", result.RestartRequired)
+	} else {
+		slog.Error("Configuration reload failed", "trigger", trigger, "error", result.Error)
+	}
+	return result
+}
+
+func (l *Live) load() (*Config, error) {
+	if l.loader == nil {
+		return nil, errNoSources
+	}
+	return l.loader.Load()
+}
+
+var errNoSources = errors.New("configuration has no sources to reload")
+
+// apply copies the reload-tagged fields of loaded into a copy of the current
+// configuration and publishes it when anything changed.
+func (l *Live) apply(loaded *Config, result *ReloadResult) {
+	previous := l.Get()
+	next := *previous
+	next.CORS.AllowedOrigins = append([]string(nil), previous.CORS.AllowedOrigins...)
+
+	incoming := map[string]reflect.Value{}
+	walk(reflect.ValueOf(loaded).Elem(), "", func(field reflect.Value, info fieldInfo) {
+		incoming[info.key] = field
+	})
+	walk(reflect.ValueOf(&next).Elem(), "", func(field reflect.Value, info fieldInfo) {
+		value := incoming[info.key]
+		if reflect.DeepEqual(field.Interface(), value.Interface()) {
+			return
+		}
+		if !info.reload {
+			result.RestartRequired = append(result.RestartRequired, info.key)
+			return
+		}
+		field.Set(value)
+		result.Applied = append(result.Applied, info.key)
+	})
+
+	if len(result.Applied) == 0 {
+		return
+	}
+	l.current.Store(&next)
+	l.status.Version++
+	l.status.ActiveAt = result.At
+	for _, fn := range l.listeners {
+		fn(previous, &next)
+	}
+}
+
+// Watch reloads on SIGHUP and when a file read by the loader changes,
+// checking the files every interval; an interval of 0 only listens for
+// SIGHUP. Call the returned function to stop.
+func (l *Live) Watch(interval time.Duration) (stop func()) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+	}
+
+	go func() {
+		seen := l.fileVersions()
+		for {
+			select {
+			case <-hangup:
+				l.Reload(ReloadSignal)
+				seen = l.fileVersions()
+			case <-tick:
+				if current := l.fileVersions(); !reflect.DeepEqual(current, seen) {
+					seen = current
+					l.Reload(ReloadFile)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(hangup)
+		if ticker != nil {
+			ticker.Stop()
+		}
+		close(done)
+	}
+}
+
+// fileVersions maps each watched file to its modification time and size; a
+// missing file maps to the zero version.
+func (l *Live) fileVersions() map[string]string {
+	versions := map[string]string{}
+	if l.loader == nil {
+		return versions
+	}
+	for _, path := range l.loader.Files() {
+		if info, err := os.Stat(path); err == nil {
+			versions[path] = fmt.Sprintf("%s/%d", info.ModTime(), info.Size())
+		} else {
+			versions[path] = ""
+		}
+	}
+	return versions
+}
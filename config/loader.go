@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-backend/secrets"
+
+	"github.com/joho/godotenv"
+	"go.yaml.in/yaml/v3"
+)
+
+// devJWTSecret signs tokens outside production when JWT_SECRET is unset.
+const devJWTSecret = "default_secret_key"
+
+// Loader reads the configuration. It registers its flags on the given flag
+// set; call Load after the flags are parsed.
+type Loader struct {
+	flags       *flag.FlagSet
+	file        *string
+	secrets     *string
+	environment *string
+	port        *int
+	logLevel    *string
+}
+
+func NewLoader(flags *flag.FlagSet) *Loader {
+	return &Loader{
+		flags:       flags,
+		file:        flags.String("config", "", "Path to a YAML config file (default $CONFIG_FILE)"),
+		secrets:     flags.String("secrets", "", "Path to the encrypted secrets file (default $SECRETS_FILE)"),
+		environment: flags.String("env", "", "Environment: development, staging or production (overrides ENVIRONMENT)"),
+		port:        flags.Int("port", 0, "HTTP port (overrides PORT)"),
+		logLevel:    flags.String("log-level", "", "Log level: debug, info, warn or error (overrides LOG_LEVEL)"),
+	}
+}
+
+// dotEnvFile is read from the working directory when present.
+const dotEnvFile = ".env"
+
+// Load applies defaults, the YAML file, the encrypted secrets file, .env and
+// the environment, then the flags, and validates the result. The error lists
+// every problem found. Load reads every source again, so it is also used to
+// reload the configuration.
+func (l *Loader) Load() (*Config, error) {
+	env, err := l.env()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	configFile, secretsFile := l.paths(env)
+	if configFile != "" {
+		if err := loadFile(cfg, configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if secretsFile != "" {
+		if err := loadSecrets(cfg, secretsFile, env); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg, func(name string, secret bool) (string, bool, error) {
+		return lookupEnv(env, name, secret)
+	}); err != nil {
+		return nil, err
+	}
+
+	l.flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "env":
+			cfg.Server.Environment = *l.environment
+		case "port":
+			cfg.Server.Port = *l.port
+		case "log-level":
+			cfg.Log.Level = *l.logLevel
+		}
+	})
+
+	if cfg.JWT.Secret == "" && !cfg.Server.Production() {
+		log.Println("JWT_SECRET is not set, using the development secret")
+		cfg.JWT.Secret = devJWTSecret
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Files lists the files Load reads, for watching them for changes.
+func (l *Loader) Files() []string {
+	files := []string{dotEnvFile}
+	env, err := l.env()
+	if err != nil {
+		return files
+	}
+	configFile, secretsFile := l.paths(env)
+	for _, path := range []string{configFile, secretsFile} {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// env looks variables up in the process environment, then in .env. The
+// .env file is read on every call and never copied into the process
+// environment, so edits to it take effect on reload.
+func (l *Loader) env() (func(string) (string, bool), error) {
+	dotEnv, err := godotenv.Read(dotEnvFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", dotEnvFile, err)
+	}
+	return func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		value, ok := dotEnv[name]
+		return value, ok
+	}, nil
+}
+
+// paths returns the YAML config file and the secrets file, from the flags
+// or the environment.
+func (l *Loader) paths(env func(string) (string, bool)) (configFile, secretsFile string) {
+	configFile, secretsFile = *l.file, *l.secrets
+	if configFile == "" {
+		configFile, _ = env("CONFIG_FILE")
+	}
+	if secretsFile == "" {
+		secretsFile, _ = env("SECRETS_FILE")
+	}
+	return configFile, secretsFile
+}
+
+// loadFile overlays a YAML file. Unknown keys are errors so typos surface.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadSecrets overlays the encrypted secrets file, decrypted with the master
+// key. Its keys are environment variable names; unknown names are errors so
+// typos surface.
+func loadSecrets(cfg *Config, path string, env func(string) (string, bool)) error {
+	key, err := masterKey(env)
+	if err != nil {
+		return err
+	}
+
+	values, err := secrets.ReadFile(path, key)
+	if err != nil {
+		return fmt.Errorf("secrets file %s: %w", path, err)
+	}
+
+	var unknown []error
+	for name := range values {
+		if !KnownEnv(name) {
+			unknown = append(unknown, fmt.Errorf("secrets file %s: unknown setting %s", path, name))
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.Join(unknown...)
+	}
+
+	return applyEnv(cfg, func(name string, _ bool) (string, bool, error) {
+		value, ok := values[name]
+		return value, ok, nil
+	})
+}
+
+// MasterKey reads the secrets file key from SECRETS_KEY or the file named
+// by SECRETS_KEY_FILE.
+func MasterKey() ([]byte, error) {
+	return masterKey(os.LookupEnv)
+}
+
+func masterKey(env func(string) (string, bool)) ([]byte, error) {
+	encoded, ok, err := lookupEnv(env, "SECRETS_KEY", true)
+	if err != nil {
+		return nil, fmt.Errorf("SECRETS_KEY: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("SECRETS_KEY: is required to decrypt the secrets file, set it or SECRETS_KEY_FILE")
+	}
+	key, err := secrets.ParseKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("SECRETS_KEY: %w", err)
+	}
+	return key, nil
+}
+
+// KnownEnv reports whether name is the environment variable of a setting.
+func KnownEnv(name string) bool {
+	known := false
+	walk(reflect.ValueOf(Default()).Elem(), "", func(_ reflect.Value, info fieldInfo) {
+		for _, env := range info.envNames {
+			known = known || env == name
+		}
+	})
+	return known
+}
+
+// SecretEnv lists the environment variables of the secret settings.
+func SecretEnv() []string {
+	var names []string
+	walk(reflect.ValueOf(Default()).Elem(), "", func(_ reflect.Value, info fieldInfo) {
+		if info.secret && len(info.envNames) > 0 {
+			names = append(names, info.envNames[0])
+		}
+	})
+	return names
+}
+
+// lookupEnv reads an environment variable. Secrets may instead name a file
+// in NAME_FILE, as with Docker secrets and systemd credentials; the file's
+// trailing newline is dropped.
+func lookupEnv(env func(string) (string, bool), name string, secret bool) (string, bool, error) {
+	value, ok := env(name)
+	if !secret {
+		return value, ok, nil
+	}
+
+	path, fromFile := env(name + "_FILE")
+	if !fromFile {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("set only one of %s and %s_FILE", name, name)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// applyEnv sets every field whose env tag names a set variable. A tag may
+// list several names; the first one set wins.
+func applyEnv(cfg *Config, lookup func(name string, secret bool) (string, bool, error)) error {
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), "", func(field reflect.Value, info fieldInfo) {
+		for _, name := range info.envNames {
+			raw, ok, err := lookup(name, info.secret)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			if !ok {
+				continue
+			}
+			if err := setValue(field, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			return
+		}
+	})
+	return errors.Join(errs...)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, use e.g. 30s, 5m or 1h", raw)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, use true or false", raw)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+type fieldInfo struct {
+	key      string
+	envNames []string
+	secret   bool
+	reload   bool
+}
+
+// walk calls fn for every leaf field with its dotted YAML key.
+func walk(v reflect.Value, prefix string, fn func(reflect.Value, fieldInfo)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := strings.SplitN(sf.Tag.Get("yaml"), ",", 2)[0]
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			walk(field, key, fn)
+			continue
+		}
+
+		info := fieldInfo{
+			key:    key,
+			secret: sf.Tag.Get("secret") == "true",
+			reload: sf.Tag.Get("reload") == "true",
+		}
+		if env := sf.Tag.Get("env"); env != "" {
+			info.envNames = strings.Split(env, ",")
+		}
+		fn(field, info)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redacted replaces secret values when the config is shown.
+const redacted = "******"
+
+// Field is one setting as shown to operators, with secrets redacted.
+type Field struct {
+	Key   string `json:"key"`
+	Env   string `json:"env,omitempty"`
+	Value string `json:"value"`
+	// Reloadable settings change without a restart
+	Reloadable bool `json:"reloadable"`
+}
+
+// Fields lists every setting in declaration order, secrets redacted.
+func (c *Config) Fields() []Field {
+	var fields []Field
+	walk(reflect.ValueOf(c).Elem(), "", func(field reflect.Value, info fieldInfo) {
+		value := formatValue(field)
+		if info.secret && value != "" {
+			value = redacted
+		}
+		env := ""
+		if len(info.envNames) > 0 {
+			env = info.envNames[0]
+		}
+		fields = append(fields, Field{Key: info.key, Env: env, Value: value, Reloadable: info.reload})
+	})
+	return fields
+}
+
+// String prints the configuration one setting per line, secrets redacted,
+// so it is safe to log.
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, field := range c.Fields() {
+		fmt.Fprintf(&b, "%-36s %-28s %s\n", field.Key, field.Env, field.Value)
+	}
+	return b.String()
+}
+
+func formatValue(field reflect.Value) string {
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String()
+	}
+	if field.Kind() == reflect.Slice {
+		return strings.Join(field.Interface().([]string), ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-backend/reqctx"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// replicaReadKey marks a query chain as safe to serve from a replica.
+const replicaReadKey = "replicas:read"
+
+// ReadFromReplica marks the queries of db as read-only, so they may be
+// served by a read replica when DB_REPLICAS is set. Only mark queries whose
+// callers tolerate replication lag: lists and searches, not lookups that
+// decide authorization or precede a write.
+func ReadFromReplica(db *gorm.DB) *gorm.DB {
+	return db.Set(replicaReadKey, true)
+}
+
+// replicaSet routes marked reads of the primary to the healthy replicas in
+// turn, falling back to the primary when none is healthy. A user who just
+// changed data reads from the primary for the readYourWrites window.
+type replicaSet struct {
+	replicas       []*replica
+	next           atomic.Uint64
+	readYourWrites time.Duration
+
+	mu     sync.Mutex
+	writes map[string]time.Time // user ID -> end of their primary window
+}
+
+type replica struct {
+	index   int
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// UseReplicas connects to cfg.Replicas and routes the reads of db marked
+// with ReadFromReplica to them, checking their health every
+// DB_REPLICA_HEALTH_INTERVAL until stop is called. Without replicas it
+// does nothing. A replica that is down at start is skipped, not fatal.
+func UseReplicas(db *gorm.DB, cfg DatabaseConfig) (stop func(), err error) {
+	if len(cfg.Replicas) == 0 {
+		return func() {}, nil
+	}
+
+	set := &replicaSet{readYourWrites: cfg.ReadYourWrites, writes: map[string]time.Time{}}
+	for i, dsn := range cfg.Replicas {
+		// Statements are built by the primary, so the replica connection
+		// needs no server version, which MySQL would otherwise query here
+		replicaDialector := dialector(cfg.Driver, dsn)
+		if cfg.Driver == "mysql" {
+			replicaDialector = mysql.New(mysql.Config{DSN: dsn, SkipInitializeWithVersion: true})
+		}
+		conn, err := gorm.Open(replicaDialector, &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			return nil, fmt.Errorf("open replica %d: %w", i+1, err)
+		}
+		sqlDB, err := conn.DB()
+		if err != nil {
+			return nil, fmt.Errorf("open replica %d: %w", i+1, err)
+		}
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+		r := &replica{index: i + 1, db: conn}
+		// Assumed up so the first check logs the replicas that are not
+		r.healthy.Store(true)
+		set.replicas = append(set.replicas, r)
+	}
+
+	if err := set.register(db); err != nil {
+		return nil, err
+	}
+
+	set.checkHealth(cfg.ReplicaHealthInterval)
+	ticker := time.NewTicker(cfg.ReplicaHealthInterval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				set.checkHealth(cfg.ReplicaHealthInterval)
+				set.forgetWrites()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		for _, r := range set.replicas {
+			if sqlDB, err := r.db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		}
+	}, nil
+}
+
+func (s *replicaSet) register(db *gorm.DB) error {
+	callbacks := db.Callback()
+	steps := []error{
+		callbacks.Query().Before("gorm:query").Register("replicas:route", s.route),
+		callbacks.Row().Before("gorm:row").Register("replicas:route", s.route),
+		callbacks.Create().After("gorm:create").Register("replicas:wrote", s.wrote),
+		callbacks.Update().After("gorm:update").Register("replicas:wrote", s.wrote),
+		callbacks.Delete().After("gorm:delete").Register("replicas:wrote", s.wrote),
+		callbacks.Raw().After("gorm:raw").Register("replicas:wrote", s.wrote),
+	}
+	for _, err := range steps {
+		if err != nil {
+			return fmt.Errorf("register replica callbacks: %w", err)
+		}
+	}
+	return nil
+}
+
+// route swaps the connection of a marked read for a replica. Reads inside
+// a transaction stay on it.
+func (s *replicaSet) route(db *gorm.DB) {
+	if read, _ := db.Get(replicaReadKey); read != true {
+		return
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return
+	}
+	if s.recentlyWrote(db.Statement.Context) {
+		return
+	}
+	if r := s.pick(); r != nil {
+		db.Statement.ConnPool = r.db.Statement.ConnPool
+	}
+}
+
+// pick returns the next healthy replica, or nil when none is.
+func (s *replicaSet) pick() *replica {
+	start := s.next.Add(1)
+	for i := range s.replicas {
+		r := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// wrote starts the read-your-writes window of the user behind a statement
+// that changed data.
+func (s *replicaSet) wrote(db *gorm.DB) {
+	userID := reqctx.PrincipalID(db.Statement.Context)
+	if db.Error != nil || userID == "" || s.readYourWrites <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.writes[userID] = time.Now().Add(s.readYourWrites)
+	s.mu.Unlock()
+}
+
+func (s *replicaSet) recentlyWrote(ctx context.Context) bool {
+	userID := reqctx.PrincipalID(ctx)
+	if userID == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().Before(s.writes[userID])
+}
+
+// forgetWrites drops the windows that have ended.
+func (s *replicaSet) forgetWrites() {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for userID, until := range s.writes {
+		if !now.Before(until) {
+			delete(s.writes, userID)
+		}
+	}
+}
+
+// checkHealth pings every replica, logging when one goes down or comes back.
+func (s *replicaSet) checkHealth(timeout time.Duration) {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := r.ping(ctx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			slog.Info("Read replica is healthy, sending reads to it", "replica", r.index)
+		} else {
+			slog.Warn("Read replica is unhealthy, reads skip it", "replica", r.index, "error", err)
+		}
+	}
+}
+
+func (r *replica) ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
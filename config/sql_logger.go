@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang-backend/reqctx"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlLogger writes gorm's log through slog: failed statements as errors,
+// statements slower than slow as warnings and, at the info level, every
+// statement. Parameters are left as placeholders unless params is set, so
+// passwords, tokens and personal data stay out of the logs. Statements run
+// for a request carry its request_id.
+type sqlLogger struct {
+	level  logger.LogLevel
+	slow   time.Duration
+	params bool
+}
+
+func newSQLLogger(cfg DatabaseConfig) *sqlLogger {
+	levels := map[string]logger.LogLevel{
+		"silent": logger.Silent,
+		"error":  logger.Error,
+		"warn":   logger.Warn,
+		"info":   logger.Info,
+	}
+	return &sqlLogger{level: levels[cfg.LogLevel], slow: cfg.SlowQueryThreshold, params: cfg.LogParams}
+}
+
+// LogMode is used by db.Debug() to log every statement of one query chain.
+func (l *sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *sqlLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []any {
+		sql, rows := fc()
+		attrs := []any{"sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+		if id := reqctx.RequestID(ctx); id != "" {
+			attrs = append(attrs, "request_id", id)
+		}
+		return attrs
+	}
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		slog.ErrorContext(ctx, "SQL statement failed", append(attrs(), "error", err)...)
+	case l.slow > 0 && elapsed > l.slow && l.level >= logger.Warn:
+		slog.WarnContext(ctx, "Slow SQL statement", append(attrs(), "threshold_ms", l.slow.Milliseconds())...)
+	case l.level >= logger.Info:
+		slog.InfoContext(ctx, "SQL statement", attrs()...)
+	}
+}
+
+// ParamsFilter drops the parameters before gorm renders the statement for
+// Trace, which then shows placeholders instead of values.
+func (l *sqlLogger) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.params {
+		return sql, params
+	}
+	return sql, nil
+}
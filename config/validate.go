@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang-backend/entity"
+	"golang-backend/i18n"
+)
+
+// minProductionSecret is the shortest JWT secret accepted in production.
+const minProductionSecret = 32
+
+// problems collects validation failures so they are reported together.
+type problems []error
+
+func (p *problems) check(ok bool, setting, format string, args ...interface{}) {
+	if !ok {
+		*p = append(*p, fmt.Errorf("%s: %s", setting, fmt.Sprintf(format, args...)))
+	}
+}
+
+// Validate checks every setting and returns all problems at once, each
+// naming the environment variable to fix.
+func (c *Config) Validate() error {
+	var p problems
+
+	p.check(oneOf(c.Server.Environment, "development", "staging", "production"), "ENVIRONMENT", "must be development, staging or production, got %q", c.Server.Environment)
+	p.check(validPort(c.Server.Port), "PORT", "must be between 1 and 65535, got %d", c.Server.Port)
+	p.check(c.Server.ConfigWatchInterval >= 0, "CONFIG_WATCH_INTERVAL", "must not be negative")
+	p.check(c.Server.RequestTimeout >= 0, "REQUEST_TIMEOUT", "must not be negative")
+	p.check(validMetricsAddr(c.Server), "METRICS_ADDR", "must be empty or host:port on a port other than PORT, got %q", c.Server.MetricsAddr)
+	p.check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "LOG_LEVEL", "must be debug, info, warn or error, got %q", c.Log.Level)
+
+	db := c.Database
+	p.check(oneOf(db.Driver, "postgres", "mysql", "sqlite"), "DB_TYPE", "must be postgres, mysql or sqlite, got %q", db.Driver)
+	p.check(db.Name != "", "DB_NAME", "is required")
+	if db.Driver != "sqlite" {
+		p.check(db.Host != "", "DB_HOST", "is required")
+		p.check(validPort(db.Port), "DB_PORT", "must be between 1 and 65535, got %d", db.Port)
+		p.check(db.User != "", "DB_USER", "is required")
+	}
+	if db.Driver == "postgres" {
+		p.check(oneOf(db.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"), "DB_SSLMODE", "must be a Postgres sslmode such as disable or require, got %q", db.SSLMode)
+	}
+	p.check(db.MaxOpenConns > 0, "DB_MAX_OPEN_CONNS", "must be positive")
+	p.check(db.MaxIdleConns >= 0 && db.MaxIdleConns <= db.MaxOpenConns, "DB_MAX_IDLE_CONNS", "must be between 0 and DB_MAX_OPEN_CONNS (%d)", db.MaxOpenConns)
+	p.check(db.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME", "must not be negative")
+	p.check(db.StatementTimeout >= 0, "DB_STATEMENT_TIMEOUT", "must not be negative")
+	p.check(oneOf(db.LogLevel, "silent", "error", "warn", "info"), "DB_LOG_LEVEL", "must be silent, error, warn or info, got %q", db.LogLevel)
+	p.check(db.SlowQueryThreshold >= 0, "DB_SLOW_QUERY_THRESHOLD", "must not be negative")
+	p.check(!db.LogParams || !c.Server.Production(), "DB_LOG_PARAMS", "must be false in production, parameters hold passwords and personal data")
+	p.check(db.ReplicaHealthInterval > 0, "DB_REPLICA_HEALTH_INTERVAL", "must be positive")
+	p.check(db.ReadYourWrites >= 0, "DB_READ_YOUR_WRITES", "must not be negative")
+
+	p.check(c.JWT.Secret != "", "JWT_SECRET", "is required in production")
+	if c.Server.Production() && c.JWT.Secret != "" {
+		p.check(len(c.JWT.Secret) >= minProductionSecret, "JWT_SECRET", "must be at least %d characters in production", minProductionSecret)
+		p.check(c.JWT.Secret != devJWTSecret, "JWT_SECRET", "must not be the development secret in production")
+	}
+	p.check(c.JWT.ExpirationHours > 0, "JWT_EXPIRATION_HOURS", "must be positive")
+
+	p.check(c.SMTP.Host != "", "SMTP_HOST", "is required")
+	p.check(validPort(c.SMTP.Port), "SMTP_PORT", "must be between 1 and 65535, got %d", c.SMTP.Port)
+
+	p.check(len(c.CORS.AllowedOrigins) > 0, "CORS_ALLOWED_ORIGINS", "must be set, listing the allowed origins or * for any")
+	for _, origin := range c.CORS.AllowedOrigins {
+		p.check(origin == "*" || strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"), "CORS_ALLOWED_ORIGINS", "%q must be * or start with http:// or https://", origin)
+	}
+
+	rl := c.RateLimit
+	p.check(rl.IPRate > 0 && rl.IPBurst > 0, "RATE_LIMIT_IP_RPS/RATE_LIMIT_IP_BURST", "must be positive")
+	p.check(rl.UserRate > 0 && rl.UserBurst > 0, "RATE_LIMIT_USER_RPS/RATE_LIMIT_USER_BURST", "must be positive")
+
+	p.check(c.RBAC.RoleSweepInterval > 0, "ROLE_SWEEP_INTERVAL", "must be positive")
+	p.check(c.RBAC.RoleExpiryNotice > 0, "ROLE_EXPIRY_NOTICE", "must be positive")
+	p.check(c.RBAC.DefaultRole != entity.RoleAdmin, "DEFAULT_ROLE", "must not be %s, anyone could register as an administrator", entity.RoleAdmin)
+
+	pc := c.PrincipalCache
+	p.check(oneOf(pc.Backend, "memory", "redis", "none"), "PRINCIPAL_CACHE_BACKEND", "must be memory, redis or none, got %q", pc.Backend)
+	if pc.Backend != "none" {
+		p.check(pc.TTL > 0, "PRINCIPAL_CACHE_TTL", "must be positive")
+	}
+	if pc.Backend == "memory" {
+		p.check(pc.Size > 0, "PRINCIPAL_CACHE_SIZE", "must be positive")
+	}
+	if pc.Backend == "redis" {
+		p.check(c.Redis.Addr != "", "REDIS_ADDR", "is required when PRINCIPAL_CACHE_BACKEND=redis")
+	}
+
+	p.check(i18n.Supported(c.Locale.Default), "DEFAULT_LOCALE", "must be one of %s, got %q", strings.Join(i18n.Locales(), ", "), c.Locale.Default)
+
+	return errors.Join(p...)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func validMetricsAddr(s ServerConfig) bool {
+	if s.MetricsAddr == "" {
+		return true
+	}
+	_, portText, err := net.SplitHostPort(s.MetricsAddr)
+	if err != nil {
+		return false
+	}
+	port, err := strconv.Atoi(portText)
+	return err == nil && validPort(port) && port != s.Port
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"golang-backend/config"
+	"golang-backend/dto"
+	"golang-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConfigController struct {
+	live *config.Live
+}
+
+func NewConfigController(live *config.Live) *ConfigController {
+	return &ConfigController{live: live}
+}
+
+// GetConfig godoc
+// @Summary      Show the active configuration
+// @Description  Show the configuration in effect with secrets redacted, its version and the result of the last reload (SIGHUP or config file change)
+// @Tags         Config
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  utils.Response{data=dto.ConfigResponse}
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Router       /admin/config [get]
+func (cc *ConfigController) GetConfig(c *gin.Context) {
+	utils.SuccessResponse(c, "Configuration retrieved successfully", dto.ConfigResponse{
+		ReloadStatus: cc.live.Status(),
+		Settings:     cc.live.Get().Fields(),
+	})
+}
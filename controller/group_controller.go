@@ -0,0 +1,358 @@
+package controller
+
+import (
+	"golang-backend/apperror"
+	"golang-backend/dto"
+	"golang-backend/reqctx"
+	"golang-backend/service"
+	"golang-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupController struct {
+	service service.GroupService
+}
+
+func NewGroupController(service service.GroupService) *GroupController {
+	return &GroupController{service: service}
+}
+
+// ListGroups godoc
+// @Summary      List groups
+// @Description  List the groups of the active organization
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string  false  "Organization ID or slug"
+// @Param        page            query     int     false  "Page number" default(1)
+// @Param        per_page        query     int     false  "Items per page" default(15)
+// @Param        search          query     string  false  "Search by name"
+// @Param        parent_id       query     string  false  "Only direct subgroups of this group"
+// @Success      200   {object}  utils.Response{data=[]dto.GroupResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Router       /admin/groups [get]
+func (gc *GroupController) ListGroups(c *gin.Context) {
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	page, perPage := utils.GetPaginationParams(c)
+	filters := map[string]interface{}{
+		"search":    c.Query("search"),
+		"parent_id": c.Query("parent_id"),
+	}
+
+	result, err := gc.service.ListGroups(c.Request.Context(), organizationID, filters, page, perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Groups retrieved successfully", result.Items, utils.BuildMeta(result.Pagination, 0))
+}
+
+// CreateGroup godoc
+// @Summary      Create a group
+// @Description  Create a group in the active organization, optionally nested under another group
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string                  false  "Organization ID or slug"
+// @Param        group           body      dto.CreateGroupRequest  true   "Group Data"
+// @Success      201   {object}  utils.Response{data=dto.GroupResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/groups [post]
+func (gc *GroupController) CreateGroup(c *gin.Context) {
+	var input dto.CreateGroupRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	group, err := gc.service.CreateGroup(c.Request.Context(), organizationID, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Group created successfully", group)
+}
+
+// GetGroup godoc
+// @Summary      Get a group
+// @Description  Get a group with its roles and the roles inherited from parent groups
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string  false  "Organization ID or slug"
+// @Param        id              path      string  true   "Group ID"
+// @Success      200   {object}  utils.Response{data=dto.GroupResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/groups/{id} [get]
+func (gc *GroupController) GetGroup(c *gin.Context) {
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	group, err := gc.service.GetGroup(c.Request.Context(), organizationID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Group retrieved successfully", group)
+}
+
+// UpdateGroup godoc
+// @Summary      Update a group
+// @Description  Rename a group or move it under another parent. Moves that would create a cycle are rejected.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string                  false  "Organization ID or slug"
+// @Param        id              path      string                  true   "Group ID"
+// @Param        group           body      dto.UpdateGroupRequest  true   "Group Data"
+// @Success      200   {object}  utils.Response{data=dto.GroupResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/groups/{id} [put]
+func (gc *GroupController) UpdateGroup(c *gin.Context) {
+	var input dto.UpdateGroupRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	group, err := gc.service.UpdateGroup(c.Request.Context(), organizationID, c.Param("id"), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Group updated successfully", group)
+}
+
+// DeleteGroup godoc
+// @Summary      Delete a group
+// @Description  Delete a group; its subgroups move up to its parent
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string  false  "Organization ID or slug"
+// @Param        id              path      string  true   "Group ID"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/groups/{id} [delete]
+func (gc *GroupController) DeleteGroup(c *gin.Context) {
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	if err := gc.service.DeleteGroup(c.Request.Context(), organizationID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Group deleted successfully", nil)
+}
+
+// ListGroupMembers godoc
+// @Summary      List group members
+// @Description  List the users added directly to a group
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string  false  "Organization ID or slug"
+// @Param        id              path      string  true   "Group ID"
+// @Param        page            query     int     false  "Page number" default(1)
+// @Param        per_page        query     int     false  "Items per page" default(15)
+// @Success      200   {object}  utils.Response{data=[]dto.GroupMemberResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/groups/{id}/members [get]
+func (gc *GroupController) ListGroupMembers(c *gin.Context) {
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	page, perPage := utils.GetPaginationParams(c)
+
+	result, err := gc.service.ListGroupMembers(c.Request.Context(), organizationID, c.Param("id"), page, perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Group members retrieved successfully", result.Items, utils.BuildMeta(result.Pagination, 0))
+}
+
+// AddGroupMember godoc
+// @Summary      Add a user to a group
+// @Description  Add an active member of the organization to a group
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string                     false  "Organization ID or slug"
+// @Param        id              path      string                     true   "Group ID"
+// @Param        member          body      dto.AddGroupMemberRequest  true   "Member"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/groups/{id}/members [post]
+func (gc *GroupController) AddGroupMember(c *gin.Context) {
+	var input dto.AddGroupMemberRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	if err := gc.service.AddGroupMember(c.Request.Context(), organizationID, c.Param("id"), input.UserID, reqctx.PrincipalID(c.Request.Context())); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Group member added successfully", nil)
+}
+
+// RemoveGroupMember godoc
+// @Summary      Remove a user from a group
+// @Description  Remove a user from a group
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string  false  "Organization ID or slug"
+// @Param        id              path      string  true   "Group ID"
+// @Param        userId          path      string  true   "User ID"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/groups/{id}/members/{userId} [delete]
+func (gc *GroupController) RemoveGroupMember(c *gin.Context) {
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	if err := gc.service.RemoveGroupMember(c.Request.Context(), organizationID, c.Param("id"), c.Param("userId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Group member removed successfully", nil)
+}
+
+// GrantGroupRole godoc
+// @Summary      Grant a role to a group
+// @Description  Grant a role to every member of a group and of its subgroups
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string                     false  "Organization ID or slug"
+// @Param        id              path      string                     true   "Group ID"
+// @Param        role            body      dto.GrantGroupRoleRequest  true   "Role name"
+// @Success      200   {object}  utils.Response{data=dto.GroupResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/groups/{id}/roles [post]
+func (gc *GroupController) GrantGroupRole(c *gin.Context) {
+	var input dto.GrantGroupRoleRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	group, err := gc.service.GrantGroupRole(c.Request.Context(), organizationID, c.Param("id"), input.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Role granted to group successfully", group)
+}
+
+// RevokeGroupRole godoc
+// @Summary      Revoke a role from a group
+// @Description  Revoke a role granted to a group
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string  false  "Organization ID or slug"
+// @Param        id              path      string  true   "Group ID"
+// @Param        roleId          path      string  true   "Role ID"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/groups/{id}/roles/{roleId} [delete]
+func (gc *GroupController) RevokeGroupRole(c *gin.Context) {
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	if err := gc.service.RevokeGroupRole(c.Request.Context(), organizationID, c.Param("id"), c.Param("roleId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Role revoked from group successfully", nil)
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang-backend/dto"
+	"golang-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	db *sql.DB
+}
+
+func NewHealthController(db *sql.DB) *HealthController {
+	return &HealthController{db: db}
+}
+
+// Health pings the database and reports its connection pool. It is served
+// outside /api, so it is left out of the Swagger docs.
+func (hc *HealthController) Health(c *gin.Context) {
+	if err := hc.db.PingContext(c.Request.Context()); err != nil {
+		utils.ErrorResponse(c, "Database ping failed", http.StatusInternalServerError, nil)
+		return
+	}
+
+	utils.SuccessResponse(c, "API is running and DB is connected", dto.HealthResponse{
+		Database: dto.NewPoolStats(hc.db.Stats()),
+	})
+}
+
+// Metrics exposes the connection pool statistics in the Prometheus text
+// format for scraping.
+func (hc *HealthController) Metrics(c *gin.Context) {
+	stats := hc.db.Stats()
+	var out strings.Builder
+	metric := func(name, kind, help string, value interface{}) {
+		fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+	metric("db_pool_max_open_connections", "gauge", "Maximum number of open connections (DB_MAX_OPEN_CONNS).", stats.MaxOpenConnections)
+	metric("db_pool_open_connections", "gauge", "Established connections, in use and idle.", stats.OpenConnections)
+	metric("db_pool_in_use_connections", "gauge", "Connections currently in use.", stats.InUse)
+	metric("db_pool_idle_connections", "gauge", "Idle connections.", stats.Idle)
+	metric("db_pool_wait_count_total", "counter", "Connections waited for.", stats.WaitCount)
+	metric("db_pool_wait_duration_seconds_total", "counter", "Time blocked waiting for a connection.", stats.WaitDuration.Seconds())
+	metric("db_pool_max_idle_closed_total", "counter", "Connections closed due to DB_MAX_IDLE_CONNS.", stats.MaxIdleClosed)
+	metric("db_pool_max_lifetime_closed_total", "counter", "Connections closed due to DB_CONN_MAX_LIFETIME.", stats.MaxLifetimeClosed)
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(out.String()))
+}
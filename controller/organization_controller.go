@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"golang-backend/apperror"
+	"golang-backend/dto"
+	"golang-backend/middleware"
+	"golang-backend/reqctx"
+	"golang-backend/service"
+	"golang-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationController struct {
+	service service.OrganizationService
+}
+
+func NewOrganizationController(service service.OrganizationService) *OrganizationController {
+	return &OrganizationController{service: service}
+}
+
+// CreateOrganization godoc
+// @Summary      Create an organization
+// @Description  Create an organization; the creator becomes its owner
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        organization  body      dto.CreateOrganizationRequest  true  "Organization Data"
+// @Success      201   {object}  utils.Response{data=dto.OrganizationResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /organizations [post]
+func (oc *OrganizationController) CreateOrganization(c *gin.Context) {
+	var input dto.CreateOrganizationRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	organization, err := oc.service.CreateOrganization(c.Request.Context(), reqctx.PrincipalID(c.Request.Context()), input.Name, input.Slug)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Organization created successfully", organization)
+}
+
+// ListOrganizations godoc
+// @Summary      List my organizations
+// @Description  List the organizations the current user belongs to or is invited to
+// @Tags         Organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  utils.Response{data=[]dto.OrganizationResponse}
+// @Failure      401   {object}  utils.Response
+// @Router       /organizations [get]
+func (oc *OrganizationController) ListOrganizations(c *gin.Context) {
+	organizations, err := oc.service.ListOrganizations(c.Request.Context(), reqctx.PrincipalID(c.Request.Context()))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Organizations retrieved successfully", organizations)
+}
+
+// SwitchOrganization godoc
+// @Summary      Switch active organization
+// @Description  Make an organization the default tenant and get a token scoped to it
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        organization  body      dto.OrganizationRequest  true  "Organization"
+// @Success      200   {object}  utils.Response{data=dto.SwitchOrganizationResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /organizations/switch [post]
+func (oc *OrganizationController) SwitchOrganization(c *gin.Context) {
+	var input dto.OrganizationRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	response, err := oc.service.SwitchOrganization(c.Request.Context(), reqctx.PrincipalID(c.Request.Context()), input.OrganizationID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Organization switched successfully", response)
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept an organization invitation
+// @Description  Join an organization the current user was invited to
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        organization  body      dto.OrganizationRequest  true  "Organization"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /organizations/accept [post]
+func (oc *OrganizationController) AcceptInvitation(c *gin.Context) {
+	var input dto.OrganizationRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	if err := oc.service.AcceptInvitation(c.Request.Context(), input.OrganizationID, reqctx.PrincipalID(c.Request.Context())); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Invitation accepted successfully", nil)
+}
+
+// ListMembers godoc
+// @Summary      List members of the active organization
+// @Description  List members and pending invitations of the organization resolved for the request
+// @Tags         Organizations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string  false  "Organization ID or slug"
+// @Param        page            query     int     false  "Page number" default(1)
+// @Param        per_page        query     int     false  "Items per page" default(15)
+// @Success      200   {object}  utils.Response{data=[]dto.MemberResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Router       /organizations/current/members [get]
+func (oc *OrganizationController) ListMembers(c *gin.Context) {
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	page, perPage := utils.GetPaginationParams(c)
+
+	result, err := oc.service.ListMembers(c.Request.Context(), organizationID, page, perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Members retrieved successfully", result.Items, utils.BuildMeta(result.Pagination, 0))
+}
+
+// InviteMember godoc
+// @Summary      Invite a member to the active organization
+// @Description  Invite a registered user by email with an organization role (defaults to member)
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Organization  header    string                   false  "Organization ID or slug"
+// @Param        invitation      body      dto.InviteMemberRequest  true   "Invitation"
+// @Success      201   {object}  utils.Response{data=dto.MemberResponse}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /organizations/current/members [post]
+func (oc *OrganizationController) InviteMember(c *gin.Context) {
+	var input dto.InviteMemberRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	organizationID, ok := requireTenant(c)
+	if !ok {
+		return
+	}
+
+	member, err := oc.service.InviteMember(c.Request.Context(), organizationID, reqctx.PrincipalID(c.Request.Context()), input.Email, input.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Member invited successfully", member)
+}
+
+// requireTenant returns the organization resolved by TenantMiddleware,
+// answering 400 when the request has none.
+func requireTenant(c *gin.Context) (string, bool) {
+	organizationID := middleware.TenantID(c)
+	if organizationID == "" {
+		c.Error(apperror.BadRequest.New("No active organization; send the X-Organization header or switch organization"))
+		return "", false
+	}
+	return organizationID, true
+}
@@ -1,22 +1,26 @@
 package controller
 
 import (
-	"net/http"
+	"sort"
 
+	"golang-backend/apperror"
 	"golang-backend/dto"
 	"golang-backend/entity"
+	"golang-backend/middleware"
+	"golang-backend/reqctx"
+	"golang-backend/service"
 	"golang-backend/utils"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type RoleController struct {
-	DB *gorm.DB
+	service service.RBACService
+	audit   service.AuditService
 }
 
-func NewRoleController(db *gorm.DB) *RoleController {
-	return &RoleController{DB: db}
+func NewRoleController(service service.RBACService, audit service.AuditService) *RoleController {
+	return &RoleController{service: service, audit: audit}
 }
 
 // CreateRole godoc
@@ -31,21 +35,24 @@ func NewRoleController(db *gorm.DB) *RoleController {
 // @Failure      400   {object}  utils.Response
 // @Failure      401   {object}  utils.Response
 // @Failure      403   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
 // @Router       /admin/roles [post]
 func (rc *RoleController) CreateRole(c *gin.Context) {
 	var input dto.CreateRoleRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, err.Error(), http.StatusBadRequest, nil)
+		c.Error(apperror.Bind(err))
 		return
 	}
 
-	role := entity.Role{Name: input.Name}
-	if err := rc.DB.Create(&role).Error; err != nil {
-		utils.ErrorResponse(c, "Role already exists", http.StatusConflict, nil)
+	role, err := rc.service.CreateRole(c.Request.Context(), input.Name)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	rc.record(c, entity.AuditRoleCreated, "role", role.ID, nil, roleSnapshot(role))
+
 	utils.SuccessResponse(c, "Role created successfully", role)
 }
 
@@ -61,27 +68,30 @@ func (rc *RoleController) CreateRole(c *gin.Context) {
 // @Failure      400   {object}  utils.Response
 // @Failure      401   {object}  utils.Response
 // @Failure      403   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
 // @Router       /admin/permissions [post]
 func (rc *RoleController) CreatePermission(c *gin.Context) {
 	var input dto.CreatePermissionRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, err.Error(), http.StatusBadRequest, nil)
+		c.Error(apperror.Bind(err))
 		return
 	}
 
-	permission := entity.Permission{Name: input.Name}
-	if err := rc.DB.Create(&permission).Error; err != nil {
-		utils.ErrorResponse(c, "Permission already exists", http.StatusConflict, nil)
+	permission, err := rc.service.CreatePermission(c.Request.Context(), input.Name)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	rc.record(c, entity.AuditPermissionCreated, "permission", permission.ID, nil, permissionSnapshot(permission))
+
 	utils.SuccessResponse(c, "Permission created successfully", permission)
 }
 
 // AssignRoleToUser godoc
 // @Summary      Assign a role to a user
-// @Description  Assign a role to a user (Admin only)
+// @Description  Assign a role to a user, optionally only between starts_at and expires_at (Admin only)
 // @Tags         Roles
 // @Accept       json
 // @Produce      json
@@ -92,37 +102,32 @@ func (rc *RoleController) CreatePermission(c *gin.Context) {
 // @Failure      401   {object}  utils.Response
 // @Failure      403   {object}  utils.Response
 // @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
 // @Router       /admin/assign-role [post]
 func (rc *RoleController) AssignRoleToUser(c *gin.Context) {
 	var input dto.AssignRoleRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, err.Error(), http.StatusBadRequest, nil)
+		c.Error(apperror.Bind(err))
 		return
 	}
 
-	var user entity.User
-	if err := rc.DB.Preload("Roles").First(&user, "id = ?", input.UserID).Error; err != nil {
-		utils.ErrorResponse(c, "User not found", http.StatusNotFound, nil)
-		return
+	grant := service.RoleGrant{
+		GrantedBy: reqctx.PrincipalID(c.Request.Context()),
+		StartsAt:  input.StartsAt,
+		ExpiresAt: input.ExpiresAt,
 	}
 
-	var role entity.Role
-	if err := rc.DB.Where("name = ?", input.Role).First(&role).Error; err != nil {
-		utils.ErrorResponse(c, "Role not found", http.StatusNotFound, nil)
+	if err := rc.service.AssignRoleToUser(c.Request.Context(), input.UserID, input.Role, grant); err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Check if already has role
-	if user.HasRole(input.Role) {
-		utils.ErrorResponse(c, "User already has this role", http.StatusBadRequest, nil)
-		return
-	}
-
-	if err := rc.DB.Model(&user).Association("Roles").Append(&role); err != nil {
-		utils.ErrorResponse(c, "Failed to assign role", http.StatusInternalServerError, nil)
-		return
-	}
+	rc.record(c, entity.AuditUserRoleAssigned, "user", input.UserID, nil, map[string]interface{}{
+		"role":       input.Role,
+		"starts_at":  input.StartsAt,
+		"expires_at": input.ExpiresAt,
+	})
 
 	utils.SuccessResponse(c, "Role assigned successfully", nil)
 }
@@ -140,31 +145,682 @@ func (rc *RoleController) AssignRoleToUser(c *gin.Context) {
 // @Failure      401   {object}  utils.Response
 // @Failure      403   {object}  utils.Response
 // @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
 // @Router       /admin/assign-permission [post]
 func (rc *RoleController) AssignPermissionToRole(c *gin.Context) {
 	var input dto.AssignPermissionRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, err.Error(), http.StatusBadRequest, nil)
+		c.Error(apperror.Bind(err))
 		return
 	}
 
-	var role entity.Role
-	if err := rc.DB.Preload("Permissions").Where("name = ?", input.RoleName).First(&role).Error; err != nil {
-		utils.ErrorResponse(c, "Role not found", http.StatusNotFound, nil)
+	role, err := rc.service.AssignPermissionToRole(c.Request.Context(), input.RoleName, input.PermissionName)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	var permission entity.Permission
-	if err := rc.DB.Where("name = ?", input.PermissionName).First(&permission).Error; err != nil {
-		utils.ErrorResponse(c, "Permission not found", http.StatusNotFound, nil)
+	rc.record(c, entity.AuditRolePermissionAdded, "role", role.ID, nil, map[string]string{"role": role.Name, "permission": input.PermissionName})
+
+	utils.SuccessResponse(c, "Permission assigned to role successfully", nil)
+}
+
+// ListRoles godoc
+// @Summary      List roles
+// @Description  Get paginated list of roles with their permissions (Admin only)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        per_page query int false "Items per page" default(15)
+// @Param        search query string false "Search by role name"
+// @Success      200   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Router       /admin/roles [get]
+func (rc *RoleController) ListRoles(c *gin.Context) {
+	page, perPage := utils.GetPaginationParams(c)
+	filters := map[string]interface{}{
+		"search": c.Query("search"),
+	}
+
+	result, err := rc.service.ListRoles(c.Request.Context(), filters, page, perPage)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := rc.DB.Model(&role).Association("Permissions").Append(&permission); err != nil {
-		utils.ErrorResponse(c, "Failed to assign permission", http.StatusInternalServerError, nil)
+	meta := utils.BuildMeta(result.Pagination, 0)
+	utils.PaginatedResponse(c, "Roles retrieved successfully", result.Items, meta)
+}
+
+// GetRole godoc
+// @Summary      Get a role
+// @Description  Get a role and its permissions by ID (Admin only)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true  "Role ID"
+// @Success      200   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/roles/{id} [get]
+func (rc *RoleController) GetRole(c *gin.Context) {
+	role, err := rc.service.GetRole(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	utils.SuccessResponse(c, "Permission assigned to role successfully", nil)
+	utils.SuccessResponse(c, "Role details", role)
+}
+
+// UpdateRole godoc
+// @Summary      Rename a role
+// @Description  Rename an existing role (Admin only). The admin role cannot be renamed.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                 true  "Role ID"
+// @Param        role  body      dto.UpdateRoleRequest  true  "Role Data"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/roles/{id} [put]
+func (rc *RoleController) UpdateRole(c *gin.Context) {
+	var input dto.UpdateRoleRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	before := rc.reloadRoleSnapshot(c, c.Param("id"))
+	role, err := rc.service.UpdateRole(c.Request.Context(), c.Param("id"), input.Name)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditRoleUpdated, "role", role.ID, before, roleSnapshot(role))
+
+	utils.SuccessResponse(c, "Role updated successfully", role)
+}
+
+// DeleteRole godoc
+// @Summary      Delete a role
+// @Description  Delete a role and remove it from every user and permission (Admin only). The admin role cannot be deleted.
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true  "Role ID"
+// @Success      200   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/roles/{id} [delete]
+func (rc *RoleController) DeleteRole(c *gin.Context) {
+	before := rc.reloadRoleSnapshot(c, c.Param("id"))
+	if err := rc.service.DeleteRole(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditRoleDeleted, "role", c.Param("id"), before, nil)
+
+	utils.SuccessResponse(c, "Role deleted successfully", nil)
+}
+
+// SyncRolePermissions godoc
+// @Summary      Replace a role's permissions
+// @Description  Replace the full permission set of a role (Admin only). An empty list removes every permission.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      string                          true  "Role ID"
+// @Param        permissions  body      dto.SyncRolePermissionsRequest  true  "Permission names"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/roles/{id}/permissions [put]
+func (rc *RoleController) SyncRolePermissions(c *gin.Context) {
+	var input dto.SyncRolePermissionsRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	before := rc.reloadRoleSnapshot(c, c.Param("id"))
+	role, err := rc.service.SyncRolePermissions(c.Request.Context(), c.Param("id"), input.Permissions)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditRolePermissionsSynced, "role", role.ID, before, roleSnapshot(role))
+
+	utils.SuccessResponse(c, "Role permissions replaced successfully", role)
+}
+
+// AddParentRole godoc
+// @Summary      Inherit from a parent role
+// @Description  Make a role inherit every permission of another role (Admin only). Edges that would create a cycle are rejected.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      string                    true  "Role ID"
+// @Param        parent  body      dto.AddParentRoleRequest  true  "Parent role name"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/roles/{id}/parents [post]
+func (rc *RoleController) AddParentRole(c *gin.Context) {
+	var input dto.AddParentRoleRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	before := rc.reloadRoleSnapshot(c, c.Param("id"))
+	role, err := rc.service.AddParentRole(c.Request.Context(), c.Param("id"), input.Parent)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditRoleParentAdded, "role", role.ID, before, roleSnapshot(role))
+
+	utils.SuccessResponse(c, "Parent role added successfully", role)
+}
+
+// RemoveParentRole godoc
+// @Summary      Stop inheriting from a parent role
+// @Description  Remove a parent from a role's inheritance list (Admin only)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string  true  "Role ID"
+// @Param        parentId  path      string  true  "Parent role ID"
+// @Success      200   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/roles/{id}/parents/{parentId} [delete]
+func (rc *RoleController) RemoveParentRole(c *gin.Context) {
+	before := rc.reloadRoleSnapshot(c, c.Param("id"))
+	if err := rc.service.RemoveParentRole(c.Request.Context(), c.Param("id"), c.Param("parentId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditRoleParentRemoved, "role", c.Param("id"), before, rc.reloadRoleSnapshot(c, c.Param("id")))
+
+	utils.SuccessResponse(c, "Parent role removed successfully", nil)
+}
+
+// GetEffectivePermissions godoc
+// @Summary      Get a role's effective permissions
+// @Description  List the permissions of a role including those inherited from parent roles (Admin only)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true  "Role ID"
+// @Success      200   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/roles/{id}/effective-permissions [get]
+func (rc *RoleController) GetEffectivePermissions(c *gin.Context) {
+	permissions, err := rc.service.GetEffectivePermissions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Effective permissions", permissions)
+}
+
+// ListPermissions godoc
+// @Summary      List permissions
+// @Description  Get paginated list of permissions (Admin only)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        per_page query int false "Items per page" default(15)
+// @Param        search query string false "Search by permission name"
+// @Success      200   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Router       /admin/permissions [get]
+func (rc *RoleController) ListPermissions(c *gin.Context) {
+	page, perPage := utils.GetPaginationParams(c)
+	filters := map[string]interface{}{
+		"search": c.Query("search"),
+	}
+
+	result, err := rc.service.ListPermissions(c.Request.Context(), filters, page, perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	meta := utils.BuildMeta(result.Pagination, 0)
+	utils.PaginatedResponse(c, "Permissions retrieved successfully", result.Items, meta)
+}
+
+// UpdatePermission godoc
+// @Summary      Rename a permission
+// @Description  Rename an existing permission (Admin only)
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                       true  "Permission ID"
+// @Param        permission  body      dto.UpdatePermissionRequest  true  "Permission Data"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/permissions/{id} [put]
+func (rc *RoleController) UpdatePermission(c *gin.Context) {
+	var input dto.UpdatePermissionRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	before := rc.reloadPermissionSnapshot(c, c.Param("id"))
+	permission, err := rc.service.UpdatePermission(c.Request.Context(), c.Param("id"), input.Name)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditPermissionUpdated, "permission", permission.ID, before, permissionSnapshot(permission))
+
+	utils.SuccessResponse(c, "Permission updated successfully", permission)
+}
+
+// DeletePermission godoc
+// @Summary      Delete a permission
+// @Description  Delete a permission and remove it from every role (Admin only)
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true  "Permission ID"
+// @Success      200   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/permissions/{id} [delete]
+func (rc *RoleController) DeletePermission(c *gin.Context) {
+	before := rc.reloadPermissionSnapshot(c, c.Param("id"))
+	if err := rc.service.DeletePermission(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditPermissionDeleted, "permission", c.Param("id"), before, nil)
+
+	utils.SuccessResponse(c, "Permission deleted successfully", nil)
+}
+
+// RevokeRoleFromUser godoc
+// @Summary      Revoke a role from a user
+// @Description  Remove a role from a user (Admin only). The last admin cannot lose the admin role.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        revocation  body      dto.RevokeRoleRequest  true  "Revocation Data"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Failure      409   {object}  utils.Response
+// @Router       /admin/revoke-role [post]
+func (rc *RoleController) RevokeRoleFromUser(c *gin.Context) {
+	var input dto.RevokeRoleRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	if err := rc.service.RevokeRoleFromUser(c.Request.Context(), input.UserID, input.Role); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditUserRoleRevoked, "user", input.UserID, map[string]string{"role": input.Role}, nil)
+
+	utils.SuccessResponse(c, "Role revoked successfully", nil)
+}
+
+// RevokePermissionFromRole godoc
+// @Summary      Revoke a permission from a role
+// @Description  Remove a permission from a role (Admin only)
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        revocation  body      dto.RevokePermissionRequest  true  "Revocation Data"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/revoke-permission [post]
+func (rc *RoleController) RevokePermissionFromRole(c *gin.Context) {
+	var input dto.RevokePermissionRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	role, err := rc.service.RevokePermissionFromRole(c.Request.Context(), input.RoleName, input.PermissionName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditRolePermissionRemoved, "role", role.ID, map[string]string{"role": role.Name, "permission": input.PermissionName}, nil)
+
+	utils.SuccessResponse(c, "Permission revoked from role successfully", nil)
+}
+
+// GrantUserPermission godoc
+// @Summary      Grant or deny a permission to a user
+// @Description  Give a single user an extra permission (allow) or block it regardless of their roles (deny). Admin only.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        grant  body      dto.GrantUserPermissionRequest  true  "Grant Data"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/grant-user-permission [post]
+func (rc *RoleController) GrantUserPermission(c *gin.Context) {
+	var input dto.GrantUserPermissionRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	userPermission, err := rc.service.GrantUserPermission(c.Request.Context(), input.UserID, input.Permission, input.Effect)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditUserPermissionGranted, "user", input.UserID, nil, map[string]string{
+		"permission": input.Permission,
+		"effect":     userPermission.Effect,
+	})
+
+	utils.SuccessResponse(c, "User permission saved successfully", userPermission)
+}
+
+// RevokeUserPermission godoc
+// @Summary      Remove a user's direct permission entry
+// @Description  Remove a direct allow or deny entry from a user (Admin only)
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        revocation  body      dto.RevokeUserPermissionRequest  true  "Revocation Data"
+// @Success      200   {object}  utils.Response
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/revoke-user-permission [post]
+func (rc *RoleController) RevokeUserPermission(c *gin.Context) {
+	var input dto.RevokeUserPermissionRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	if err := rc.service.RevokeUserPermission(c.Request.Context(), input.UserID, input.Permission); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rc.record(c, entity.AuditUserPermissionRevoked, "user", input.UserID, map[string]string{"permission": input.Permission}, nil)
+
+	utils.SuccessResponse(c, "User permission removed successfully", nil)
+}
+
+// GetMyPermissions godoc
+// @Summary      Get my effective permissions
+// @Description  List the permissions the current user holds through roles and direct grants, and those explicitly denied. Group and per-org roles of the request's organization are included
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  utils.Response{data=dto.EffectivePermissionsResponse}
+// @Failure      401   {object}  utils.Response
+// @Router       /me/permissions [get]
+func (rc *RoleController) GetMyPermissions(c *gin.Context) {
+	user, ok := currentPrincipal(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, "Permissions retrieved successfully", effectivePermissions(user))
+}
+
+// CheckMyPermissions godoc
+// @Summary      Check my permissions
+// @Description  Evaluate a batch of action/module pairs for the current user with the same rules as the API, inside the request's organization
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        checks  body      dto.PermissionCheckRequest  true  "Checks"
+// @Success      200   {object}  utils.Response{data=[]dto.PermissionCheckResult}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Router       /me/can [post]
+func (rc *RoleController) CheckMyPermissions(c *gin.Context) {
+	var input dto.PermissionCheckRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	user, ok := currentPrincipal(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, "Permissions checked successfully", checkPermissions(c, user, input.Checks))
+}
+
+// GetUserPermissions godoc
+// @Summary      Get a user's effective permissions
+// @Description  List the permissions any user holds through roles and direct grants, and those explicitly denied
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string  true   "User ID"
+// @Param        organization  query     string  false  "Organization ID or slug to evaluate in; global grants only when omitted"
+// @Success      200   {object}  utils.Response{data=dto.EffectivePermissionsResponse}
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/users/{id}/permissions [get]
+func (rc *RoleController) GetUserPermissions(c *gin.Context) {
+	user, ok := rc.inspectedPrincipal(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, "Permissions retrieved successfully", effectivePermissions(user))
+}
+
+// CheckUserPermissions godoc
+// @Summary      Check a user's permissions
+// @Description  Evaluate a batch of action/module pairs for any user with the same rules as the API
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string                      true   "User ID"
+// @Param        organization  query     string                      false  "Organization ID or slug to evaluate in; global grants only when omitted"
+// @Param        checks        body      dto.PermissionCheckRequest  true   "Checks"
+// @Success      200   {object}  utils.Response{data=[]dto.PermissionCheckResult}
+// @Failure      400   {object}  utils.Response
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Failure      404   {object}  utils.Response
+// @Router       /admin/users/{id}/can [post]
+func (rc *RoleController) CheckUserPermissions(c *gin.Context) {
+	var input dto.PermissionCheckRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperror.Bind(err))
+		return
+	}
+
+	user, ok := rc.inspectedPrincipal(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, "Permissions checked successfully", checkPermissions(c, user, input.Checks))
+}
+
+// inspectedPrincipal loads the user of the :id parameter as the API sees them
+// in the organization of the "organization" query parameter, or outside any
+// organization when it is absent.
+func (rc *RoleController) inspectedPrincipal(c *gin.Context) (*entity.User, bool) {
+	user, err := rc.service.LoadPrincipal(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+
+	middleware.ScopePrincipal(user, c.Query("organization"))
+	return user, true
+}
+
+// currentPrincipal returns the user loaded by AuthMiddleware, answering 401 when missing.
+func currentPrincipal(c *gin.Context) (*entity.User, bool) {
+	user := reqctx.Principal(c.Request.Context())
+	if user == nil {
+		c.Error(apperror.Unauthorized.New("Unauthorized"))
+	}
+	return user, user != nil
+}
+
+func effectivePermissions(user *entity.User) dto.EffectivePermissionsResponse {
+	response := dto.EffectivePermissionsResponse{
+		UserID:      user.ID,
+		Permissions: []string{},
+		Denied:      []string{},
+	}
+	for _, permission := range user.EffectivePermissions() {
+		response.Permissions = append(response.Permissions, permission.Name)
+	}
+	for _, permission := range user.DeniedPermissions() {
+		response.Denied = append(response.Denied, permission.Name)
+	}
+	sort.Strings(response.Permissions)
+	sort.Strings(response.Denied)
+	return response
+}
+
+// checkPermissions runs each check through middleware.Can, so the answers
+// match what the API itself would decide.
+func checkPermissions(c *gin.Context, user *entity.User, checks []dto.PermissionCheck) []dto.PermissionCheckResult {
+	results := make([]dto.PermissionCheckResult, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, dto.PermissionCheckResult{
+			Action:     check.Action,
+			Module:     check.Module,
+			Permission: entity.PermissionName(check.Module, check.Action),
+			Allowed:    middleware.Can(c, user, check.Action, check.Module),
+		})
+	}
+	return results
+}
+
+// record writes an audit entry for a successful admin action.
+func (rc *RoleController) record(c *gin.Context, action, targetType, targetID string, before, after interface{}) {
+	rc.audit.Record(c.Request.Context(), service.AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// reloadRoleSnapshot loads the role and returns its roleSnapshot, so audit
+// states read back from the database match those built from a returned role.
+// It is nil, not an empty map, when the role is missing.
+func (rc *RoleController) reloadRoleSnapshot(c *gin.Context, id string) interface{} {
+	role, err := rc.service.GetRole(c.Request.Context(), id)
+	if err != nil {
+		return nil
+	}
+	return roleSnapshot(role)
+}
+
+// reloadPermissionSnapshot is reloadRoleSnapshot for a permission.
+func (rc *RoleController) reloadPermissionSnapshot(c *gin.Context, id string) interface{} {
+	permission, err := rc.service.GetPermission(c.Request.Context(), id)
+	if err != nil {
+		return nil
+	}
+	return permissionSnapshot(permission)
+}
+
+func roleSnapshot(role *entity.Role) map[string]interface{} {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+	sort.Strings(permissions)
+
+	parents := make([]string, 0, len(role.Parents))
+	for _, parent := range role.Parents {
+		parents = append(parents, parent.Name)
+	}
+	sort.Strings(parents)
+
+	return map[string]interface{}{"name": role.Name, "permissions": permissions, "parents": parents}
+}
+
+func permissionSnapshot(permission *entity.Permission) map[string]interface{} {
+	return map[string]interface{}{"name": permission.Name}
 }
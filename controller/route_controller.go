@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"sort"
+
+	"golang-backend/middleware"
+	"golang-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RouteController struct {
+	engine   *gin.Engine
+	registry *middleware.RouteRegistry
+}
+
+func NewRouteController(app *gin.Engine, registry *middleware.RouteRegistry) *RouteController {
+	return &RouteController{engine: app, registry: registry}
+}
+
+// ListRoutes godoc
+// @Summary      List routes and their permissions
+// @Description  List every registered route with the permission it requires. Routes without a permission are public or only need a login.
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  utils.Response{data=[]middleware.RoutePermission}
+// @Failure      401   {object}  utils.Response
+// @Failure      403   {object}  utils.Response
+// @Router       /admin/routes [get]
+func (rc *RouteController) ListRoutes(c *gin.Context) {
+	var routes []middleware.RoutePermission
+	for _, info := range rc.engine.Routes() {
+		route, ok := rc.registry.Lookup(info.Method, info.Path)
+		if !ok {
+			route = middleware.RoutePermission{Method: info.Method, Path: info.Path}
+		}
+		routes = append(routes, route)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	utils.SuccessResponse(c, "Routes retrieved successfully", routes)
+}
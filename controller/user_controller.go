@@ -2,10 +2,13 @@ package controller
 
 import (
 	"log/slog"
-	"net/http"
 
+	"golang-backend/apperror"
 	"golang-backend/dto"
-	// "golang-backend/middleware"
+	"golang-backend/entity"
+	"golang-backend/i18n"
+	"golang-backend/middleware"
+	"golang-backend/reqctx"
 	"golang-backend/service"
 	"golang-backend/utils"
 
@@ -34,13 +37,13 @@ func (c *UserController) Login(ctx *gin.Context) {
 	var input dto.UserLoginRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
 
-	token, err := c.service.Login(input)
+	token, err := c.service.Login(ctx.Request.Context(), input)
 	if err != nil {
-		utils.ErrorResponse(ctx, "Login Failed", http.StatusUnauthorized, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -62,13 +65,16 @@ func (c *UserController) Register(ctx *gin.Context) {
 	var input dto.UserRegisterRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
+	if input.Locale == "" {
+		input.Locale = i18n.Locale(ctx)
+	}
 
-	userResponse, err := c.service.Register(input)
+	userResponse, err := c.service.Register(ctx.Request.Context(), input)
 	if err != nil {
-		utils.ErrorResponse(ctx, "Registration Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -89,12 +95,12 @@ func (c *UserController) VerifyEmail(ctx *gin.Context) {
 	var input dto.VerifyEmailRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
 
-	if err := c.service.VerifyEmail(input); err != nil {
-		utils.ErrorResponse(ctx, "Verification Failed", http.StatusBadRequest, err.Error())
+	if err := c.service.VerifyEmail(ctx.Request.Context(), input); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -115,12 +121,12 @@ func (c *UserController) ForgotPassword(ctx *gin.Context) {
 	var input dto.ForgotPasswordRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
 
-	if err := c.service.ForgotPassword(input.Email); err != nil {
-		utils.ErrorResponse(ctx, "Request Failed", http.StatusBadRequest, err.Error())
+	if err := c.service.ForgotPassword(ctx.Request.Context(), input.Email); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -141,12 +147,12 @@ func (c *UserController) ResetPassword(ctx *gin.Context) {
 	var input dto.ResetPasswordRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
 
-	if err := c.service.ResetPassword(input); err != nil {
-		utils.ErrorResponse(ctx, "Reset Failed", http.StatusBadRequest, err.Error())
+	if err := c.service.ResetPassword(ctx.Request.Context(), input); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -167,12 +173,12 @@ func (c *UserController) ResendVerificationCode(ctx *gin.Context) {
 	var input dto.ForgotPasswordRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
 
-	if err := c.service.ResendVerificationCode(input.Email); err != nil {
-		utils.ErrorResponse(ctx, "Request Failed", http.StatusBadRequest, err.Error())
+	if err := c.service.ResendVerificationCode(ctx.Request.Context(), input.Email); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -193,12 +199,12 @@ func (c *UserController) ResendResetPasswordCode(ctx *gin.Context) {
 	var input dto.ForgotPasswordRequest
 
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
 
-	if err := c.service.ResendResetPasswordCode(input.Email); err != nil {
-		utils.ErrorResponse(ctx, "Request Failed", http.StatusBadRequest, err.Error())
+	if err := c.service.ResendResetPasswordCode(ctx.Request.Context(), input.Email); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -216,15 +222,15 @@ func (c *UserController) ResendResetPasswordCode(ctx *gin.Context) {
 // @Failure      401  {object} utils.Response
 // @Router       /me [get]
 func (c *UserController) Me(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(ctx, "Unauthorized", http.StatusUnauthorized, nil)
+	userID := reqctx.PrincipalID(ctx.Request.Context())
+	if userID == "" {
+		ctx.Error(apperror.Unauthorized.New("Unauthorized"))
 		return
 	}
 
-	userResponse, err := c.service.GetMe(userID.(string))
+	userResponse, err := c.service.GetMe(ctx.Request.Context(), userID)
 	if err != nil {
-		utils.ErrorResponse(ctx, "Failed to fetch user", http.StatusInternalServerError, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -247,11 +253,11 @@ func (c *UserController) Me(ctx *gin.Context) {
 // @Router       /users [get]
 func (c *UserController) GetUsers(ctx *gin.Context) {
 	// Policy Check
-	// Assuming module name is namespaced or simple "user"
-	// if err := middleware.AuthorizeRead(ctx, "user"); err != nil {
-	// 	utils.ErrorResponse(ctx, err.Error(), http.StatusForbidden, nil)
-	// 	return
-	// }
+	// Requires "users:read" (or a wildcard such as "users:*")
+	if err := middleware.AuthorizeRead(ctx, "users"); err != nil {
+		ctx.Error(err)
+		return
+	}
 
 	search := ctx.Query("search")
 	isVerified := ctx.Query("is_verified")
@@ -259,17 +265,18 @@ func (c *UserController) GetUsers(ctx *gin.Context) {
 	sortOrder := ctx.Query("sort_order")
 
 	filters := map[string]interface{}{
-		"search":      search,
-		"is_verified": isVerified,
-		"sort_by":     sortBy,
-		"sort_order":  sortOrder,
+		"search":          search,
+		"is_verified":     isVerified,
+		"sort_by":         sortBy,
+		"sort_order":      sortOrder,
+		"organization_id": middleware.TenantID(ctx),
 	}
 
 	page, perPage := utils.GetPaginationParams(ctx)
 
-	result, err := c.service.GetUsers(filters, page, perPage)
+	result, err := c.service.GetUsers(ctx.Request.Context(), filters, page, perPage)
 	if err != nil {
-		utils.ErrorResponse(ctx, "Failed to fetch users", http.StatusInternalServerError, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -282,6 +289,92 @@ func (c *UserController) GetUsers(ctx *gin.Context) {
 	utils.PaginatedResponse(ctx, "Users retrieved successfully", result.Items, meta)
 }
 
+// GetUser godoc
+// @Summary      Get User
+// @Description  Get a single user. Requires users:read, except for the user's own record.
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object} utils.Response{data=dto.UserResponse}
+// @Failure      403  {object} utils.Response
+// @Failure      404  {object} utils.Response
+// @Router       /users/{id} [get]
+func (c *UserController) GetUser(ctx *gin.Context) {
+	user, ok := c.loadUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := middleware.AuthorizeReadResource(ctx, "users", user); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, "User details", dto.NewUserResponse(user))
+}
+
+// UpdateUser godoc
+// @Summary      Update User
+// @Description  Update a user's profile. Requires users:update, or a manager in the same department who leaves the department unchanged.
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      string                 true  "User ID"
+// @Param        input  body      dto.UpdateUserRequest  true  "User Data"
+// @Success      200  {object} utils.Response{data=dto.UserResponse}
+// @Failure      400  {object} utils.Response
+// @Failure      403  {object} utils.Response
+// @Failure      404  {object} utils.Response
+// @Router       /users/{id} [put]
+func (c *UserController) UpdateUser(ctx *gin.Context) {
+	var input dto.UpdateUserRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Bind(err))
+		return
+	}
+
+	// The department policy compares the target's roles with the manager's
+	user, err := c.service.GetUserWithRoles(ctx.Request.Context(), ctx.Param("id"), middleware.TenantID(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	update := &middleware.UserUpdate{User: user, Changes: input}
+	if err := middleware.AuthorizeEditResource(ctx, "users", update); err != nil {
+		ctx.Error(err)
+		return
+	}
+	// Per-org roles may edit members of the organization, but not their department
+	if err := middleware.AuthorizeDepartmentChange(ctx, update); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	userResponse, err := c.service.UpdateUser(ctx.Request.Context(), user, input)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, "User updated successfully", userResponse)
+}
+
+// loadUser fetches the user named by the :id parameter, recording the error
+// for the error handler when it cannot.
+func (c *UserController) loadUser(ctx *gin.Context) (*entity.User, bool) {
+	user, err := c.service.GetUser(ctx.Request.Context(), ctx.Param("id"), middleware.TenantID(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return nil, false
+	}
+	return user, true
+}
+
 // Setup2FA godoc
 // @Summary      Setup 2FA
 // @Description  Generate 2FA secret and QR code
@@ -293,15 +386,15 @@ func (c *UserController) GetUsers(ctx *gin.Context) {
 // @Failure      400  {object} utils.Response
 // @Router       /2fa/setup [post]
 func (c *UserController) Setup2FA(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(ctx, "Unauthorized", http.StatusUnauthorized, nil)
+	userID := reqctx.PrincipalID(ctx.Request.Context())
+	if userID == "" {
+		ctx.Error(apperror.Unauthorized.New("Unauthorized"))
 		return
 	}
 
-	response, err := c.service.Setup2FA(userID.(string))
+	response, err := c.service.Setup2FA(ctx.Request.Context(), userID)
 	if err != nil {
-		utils.ErrorResponse(ctx, "Failed to setup 2FA", http.StatusBadRequest, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -320,20 +413,20 @@ func (c *UserController) Setup2FA(ctx *gin.Context) {
 // @Failure      400  {object} utils.Response
 // @Router       /2fa/verify [post]
 func (c *UserController) Verify2FA(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
-		utils.ErrorResponse(ctx, "Unauthorized", http.StatusUnauthorized, nil)
+	userID := reqctx.PrincipalID(ctx.Request.Context())
+	if userID == "" {
+		ctx.Error(apperror.Unauthorized.New("Unauthorized"))
 		return
 	}
 
 	var input dto.Verify2FARequest
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(ctx, "Validation Failed", http.StatusBadRequest, err.Error())
+		ctx.Error(apperror.Bind(err))
 		return
 	}
 
-	if err := c.service.Verify2FA(userID.(string), input.Code); err != nil {
-		utils.ErrorResponse(ctx, "Verification Failed", http.StatusBadRequest, err.Error())
+	if err := c.service.Verify2FA(ctx.Request.Context(), userID, input.Code); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -0,0 +1,10 @@
+package dto
+
+import "golang-backend/config"
+
+// ConfigResponse is the configuration in effect, secrets redacted, with its
+// version and the outcome of the last reload.
+type ConfigResponse struct {
+	config.ReloadStatus
+	Settings []config.Field `json:"settings"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type CreateGroupRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description string  `json:"description" binding:"max=255"`
+	ParentID    *string `json:"parent_id"`
+}
+
+// UpdateGroupRequest changes only the fields that are sent. An empty
+// parent_id moves the group to the top level.
+type UpdateGroupRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+	ParentID    *string `json:"parent_id"`
+}
+
+type AddGroupMemberRequest struct {
+	UserID string `json:"user_id" binding:"required,ulid"`
+}
+
+type GrantGroupRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type GroupResponse struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	ParentID    *string  `json:"parent_id"`
+	Roles       []string `json:"roles"`
+	// EffectiveRoles adds the roles inherited from parent groups
+	EffectiveRoles []string  `json:"effective_roles,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type GroupMemberResponse struct {
+	UserID  string    `json:"user_id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email"`
+	AddedAt time.Time `json:"added_at"`
+}
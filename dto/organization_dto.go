@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Slug string `json:"slug" binding:"required,max=63"`
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"`
+}
+
+type OrganizationRequest struct {
+	OrganizationID string `json:"organization_id" binding:"required,ulid"`
+}
+
+type OrganizationResponse struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Slug   string   `json:"slug"`
+	Status string   `json:"status"`
+	Roles  []string `json:"roles"`
+}
+
+type MemberResponse struct {
+	UserID   string     `json:"user_id"`
+	Name     string     `json:"name"`
+	Email    string     `json:"email"`
+	Status   string     `json:"status"`
+	Roles    []string   `json:"roles"`
+	JoinedAt *time.Time `json:"joined_at"`
+}
+
+type SwitchOrganizationResponse struct {
+	OrganizationID string `json:"organization_id"`
+	Token          string `json:"token"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type CreateRoleRequest struct {
 	Name string `json:"name" binding:"required"`
 }
@@ -9,11 +11,72 @@ type CreatePermissionRequest struct {
 }
 
 type AssignRoleRequest struct {
-	UserID string `json:"user_id" binding:"required"`
-	Role   string `json:"role" binding:"required"`
+	UserID    string     `json:"user_id" binding:"required,ulid"`
+	Role      string     `json:"role" binding:"required"`
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 type AssignPermissionRequest struct {
 	RoleName       string `json:"role_name" binding:"required"`
 	PermissionName string `json:"permission_name" binding:"required"`
 }
+
+type UpdateRoleRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdatePermissionRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type RevokeRoleRequest struct {
+	UserID string `json:"user_id" binding:"required,ulid"`
+	Role   string `json:"role" binding:"required"`
+}
+
+type RevokePermissionRequest struct {
+	RoleName       string `json:"role_name" binding:"required"`
+	PermissionName string `json:"permission_name" binding:"required"`
+}
+
+type SyncRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type AddParentRoleRequest struct {
+	Parent string `json:"parent" binding:"required"`
+}
+
+type GrantUserPermissionRequest struct {
+	UserID     string `json:"user_id" binding:"required,ulid"`
+	Permission string `json:"permission" binding:"required"`
+	Effect     string `json:"effect" binding:"omitempty,oneof=allow deny"`
+}
+
+type RevokeUserPermissionRequest struct {
+	UserID     string `json:"user_id" binding:"required,ulid"`
+	Permission string `json:"permission" binding:"required"`
+}
+
+type PermissionCheck struct {
+	Action string `json:"action" binding:"required"`
+	Module string `json:"module" binding:"required"`
+}
+
+type PermissionCheckRequest struct {
+	Checks []PermissionCheck `json:"checks" binding:"required,min=1,max=100,dive"`
+}
+
+type PermissionCheckResult struct {
+	Action     string `json:"action"`
+	Module     string `json:"module"`
+	Permission string `json:"permission"`
+	Allowed    bool   `json:"allowed"`
+}
+
+type EffectivePermissionsResponse struct {
+	UserID      string   `json:"user_id"`
+	Permissions []string `json:"permissions"`
+	Denied      []string `json:"denied"`
+}
@@ -1,9 +1,13 @@
 package dto
 
+import "golang-backend/entity"
+
 type UserRegisterRequest struct {
 	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Email    string `json:"email" binding:"required,email,unique_email"`
+	Password string `json:"password" binding:"required,strong_password"`
+	// Locale defaults to the language of the request
+	Locale string `json:"locale" binding:"omitempty,oneof=id en"`
 }
 
 type UserLoginRequest struct {
@@ -24,7 +28,7 @@ type ForgotPasswordRequest struct {
 type ResetPasswordRequest struct {
 	Email       string `json:"email" binding:"required,email"`
 	Code        string `json:"code" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required,strong_password"`
 }
 
 type Setup2FAResponse struct {
@@ -36,10 +40,29 @@ type Verify2FARequest struct {
 	Code string `json:"code" binding:"required"`
 }
 
+type UpdateUserRequest struct {
+	Name       *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Department *string `json:"department" binding:"omitempty,max=100"`
+	Locale     *string `json:"locale" binding:"omitempty,oneof=id en"`
+}
+
 type UserResponse struct {
 	ID             string `json:"id"`
 	Name           string `json:"name"`
 	Email          string `json:"email"`
+	Department     string `json:"department,omitempty"`
+	Locale         string `json:"locale,omitempty"`
 	Token          string `json:"token,omitempty"`
 	IsTwoFAEnabled bool   `json:"is_two_fa_enabled"`
 }
+
+func NewUserResponse(user *entity.User) *UserResponse {
+	return &UserResponse{
+		ID:             user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		Department:     user.Department,
+		Locale:         user.Locale,
+		IsTwoFAEnabled: user.IsTwoFAEnabled,
+	}
+}
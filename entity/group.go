@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// Group is a team inside an organization. Roles granted to a group reach all
+// of its members, and the members of a subgroup also get the roles of every
+// group above it.
+type Group struct {
+	Base
+	OrganizationID string        `gorm:"type:char(26);uniqueIndex:idx_group_org_name;not null"`
+	Name           string        `gorm:"type:varchar(100);uniqueIndex:idx_group_org_name;not null"`
+	Description    string        `gorm:"type:varchar(255)"`
+	ParentID       *string       `gorm:"type:char(26);index"`
+	Parent         *Group        `gorm:"constraint:OnDelete:SET NULL"`
+	Organization   *Organization `gorm:"constraint:OnDelete:CASCADE"`
+	Roles          []*Role       `gorm:"many2many:group_roles;"`
+}
+
+// GroupMember is the group_members table linking users to groups.
+type GroupMember struct {
+	GroupID   string  `gorm:"primaryKey;type:char(26)"`
+	UserID    string  `gorm:"primaryKey;type:char(26);index"`
+	AddedBy   *string `gorm:"type:char(26)"`
+	CreatedAt time.Time
+	Group     *Group `gorm:"constraint:OnDelete:CASCADE"`
+	User      *User  `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// Lineage returns the group followed by its ancestors, nearest first. The walk
+// stops at a group it has already seen, so a bad parent link cannot loop.
+func (g *Group) Lineage() []*Group {
+	var lineage []*Group
+	seen := map[string]bool{}
+	for group := g; group != nil && !seen[group.ID]; group = group.Parent {
+		seen[group.ID] = true
+		lineage = append(lineage, group)
+	}
+	return lineage
+}
+
+// EffectiveRoles returns the roles granted to the group and its ancestors.
+func (g *Group) EffectiveRoles() []*Role {
+	seen := map[string]bool{}
+	var roles []*Role
+	for _, group := range g.Lineage() {
+		for _, role := range group.Roles {
+			if role != nil && !seen[role.ID] {
+				seen[role.ID] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// DescendsFrom reports whether the group is the given group or nested below it.
+func (g *Group) DescendsFrom(groupID string) bool {
+	for _, group := range g.Lineage() {
+		if group.ID == groupID {
+			return true
+		}
+	}
+	return false
+}
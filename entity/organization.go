@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Membership statuses. Invited members cannot act in the organization until
+// they accept.
+const (
+	MembershipInvited = "invited"
+	MembershipActive  = "active"
+)
+
+// Organization roles: the owner is whoever created the organization, members
+// are invited with RoleOrganizationMember unless another role is chosen.
+const (
+	RoleOrganizationOwner  = "owner"
+	RoleOrganizationMember = "member"
+)
+
+// TenantResources are the resources whose routes act only inside the
+// request's organization. Roles granted within an organization, through a
+// membership or a group, may hold permissions on these alone.
+var TenantResources = []string{"organizations", "members", "groups", "users"}
+
+// IsTenantPermission reports whether the permission names a tenant resource.
+// A wildcard resource, as in "*:*", reaches beyond the organization.
+func IsTenantPermission(name string) bool {
+	resource, _, ok := ParsePermission(name)
+	return ok && slices.Contains(TenantResources, resource)
+}
+
+// Organization is a tenant. Users reach its data only through a Membership.
+type Organization struct {
+	Base
+	Name string `gorm:"type:varchar(100);not null"`
+	Slug string `gorm:"type:varchar(63);uniqueIndex;not null"`
+}
+
+// Membership links a user to an organization. Its Roles apply only while that
+// organization is the active tenant of the request.
+type Membership struct {
+	Base
+	OrganizationID string  `gorm:"type:char(26);uniqueIndex:idx_membership_org_user;not null"`
+	UserID         string  `gorm:"type:char(26);uniqueIndex:idx_membership_org_user;index;not null"`
+	Status         string  `gorm:"type:varchar(20);default:active;not null"`
+	InvitedBy      *string `gorm:"type:char(26)"`
+	JoinedAt       *time.Time
+	Organization   *Organization `gorm:"constraint:OnDelete:CASCADE"`
+	User           *User         `gorm:"constraint:OnDelete:CASCADE"`
+	Roles          []*Role       `gorm:"many2many:membership_roles;"`
+}
+
+func (m *Membership) IsActive() bool {
+	return m.Status == MembershipActive
+}
+
+// Matches reports whether ref names the membership's organization by ID or slug.
+func (m *Membership) Matches(ref string) bool {
+	if m.OrganizationID == ref {
+		return true
+	}
+	return m.Organization != nil && m.Organization.Slug == ref
+}
@@ -1,7 +1,68 @@
 package entity
 
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Permission names are structured as "resource:action", e.g. "users:read".
+// Either segment may be a glob, so "users:*" covers every action on users and
+// PermissionAll grants everything (super-admin).
+const (
+	PermissionSeparator = ":"
+	PermissionAll       = "*:*"
+)
+
+var permissionNamePattern = regexp.MustCompile(`^[a-z0-9_\-.*?]+:[a-z0-9_\-.*?]+$`)
+
 type Permission struct {
 	Base
 	Name  string  `gorm:"type:varchar(100);uniqueIndex;not null"`
 	Roles []*Role `gorm:"many2many:role_permissions;"`
 }
+
+// PermissionName builds the structured name for an action on a resource.
+func PermissionName(resource, action string) string {
+	return resource + PermissionSeparator + action
+}
+
+// ParsePermission splits a structured permission name. ok is false for names
+// that are not in the resource:action format.
+func ParsePermission(name string) (resource, action string, ok bool) {
+	resource, action, ok = strings.Cut(name, PermissionSeparator)
+	if !ok || resource == "" || action == "" {
+		return "", "", false
+	}
+	return resource, action, true
+}
+
+// ValidPermissionName reports whether name is a lowercase resource:action pair.
+func ValidPermissionName(name string) bool {
+	return permissionNamePattern.MatchString(name)
+}
+
+// MatchPermission reports whether a granted permission covers the required one.
+// Each segment of the granted name is matched as a glob against the same segment
+// of the required name; names outside the structured format only match exactly.
+func MatchPermission(granted, required string) bool {
+	if granted == required {
+		return true
+	}
+
+	grantedResource, grantedAction, ok := ParsePermission(granted)
+	if !ok {
+		return false
+	}
+	requiredResource, requiredAction, ok := ParsePermission(required)
+	if !ok {
+		return false
+	}
+
+	return matchSegment(grantedResource, requiredResource) && matchSegment(grantedAction, requiredAction)
+}
+
+func matchSegment(pattern, value string) bool {
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
@@ -1,7 +1,98 @@
 package entity
 
+// RoleAdmin is the built-in super-admin role, granted every /api/admin route
+// through the *:* permission. It cannot be renamed or deleted, and its last
+// holder cannot lose it.
+const RoleAdmin = "admin"
+
+// RoleManager may edit users in their own department through the users policy.
+const RoleManager = "manager"
+
 type Role struct {
 	Base
 	Name        string        `gorm:"type:varchar(100);uniqueIndex;not null"`
 	Permissions []*Permission `gorm:"many2many:role_permissions;"`
+	// Parents are the roles this role inherits from, e.g. manager -> user.
+	// The hierarchy is a DAG; RBACService rejects edges that would close a cycle.
+	Parents []*Role `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID"`
+}
+
+// HasPermission reports whether the permission is assigned directly to the role.
+func (r *Role) HasPermission(permissionName string) bool {
+	for _, permission := range r.Permissions {
+		if permission.Name == permissionName {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantsPermission reports whether the role or any role it inherits from has a
+// permission matching permissionName, including wildcards such as "users:*".
+func (r *Role) GrantsPermission(permissionName string) bool {
+	return r.walk(func(role *Role) bool {
+		for _, permission := range role.Permissions {
+			if MatchPermission(permission.Name, permissionName) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Implies reports whether holding this role also means holding roleName,
+// either because it is the same role or because roleName is an ancestor.
+func (r *Role) Implies(roleName string) bool {
+	return r.walk(func(role *Role) bool {
+		return role.Name == roleName
+	})
+}
+
+// Ancestors returns every role this role inherits from, nearest first.
+func (r *Role) Ancestors() []*Role {
+	var ancestors []*Role
+	r.walk(func(role *Role) bool {
+		if role != r {
+			ancestors = append(ancestors, role)
+		}
+		return false
+	})
+	return ancestors
+}
+
+// EffectivePermissions returns the role's own and inherited permissions without duplicates.
+func (r *Role) EffectivePermissions() []*Permission {
+	seen := map[string]bool{}
+	var permissions []*Permission
+	r.walk(func(role *Role) bool {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				permissions = append(permissions, permission)
+			}
+		}
+		return false
+	})
+	return permissions
+}
+
+// walk visits the role and then its ancestors breadth-first, each once, until
+// visit returns true. The visited set also keeps a corrupted cyclic graph from looping.
+func (r *Role) walk(visit func(role *Role) bool) bool {
+	seen := map[string]bool{}
+	queue := []*Role{r}
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		if role == nil || seen[role.Name] {
+			continue
+		}
+		seen[role.Name] = true
+
+		if visit(role) {
+			return true
+		}
+		queue = append(queue, role.Parents...)
+	}
+	return false
 }
@@ -8,14 +8,28 @@ type User struct {
 	Base
 	Name             string `gorm:"type:varchar(100);not null"`
 	Email            string `gorm:"type:varchar(100);uniqueIndex;not null"`
+	Department       string `gorm:"type:varchar(100);index"`
 	Password         string `gorm:"not null"`
 	IsVerified       bool   `gorm:"default:false"`
 	VerificationCode string `gorm:"type:varchar(6)"`
 	ResetToken       string `gorm:"type:varchar(6)"`
 	ResetTokenExpiry time.Time
-	IsTwoFAEnabled   bool    `gorm:"default:false"`
-	TwoFASecret      string  `gorm:"type:varchar(100)"`
-	Roles            []*Role `gorm:"many2many:user_roles;"`
+	IsTwoFAEnabled   bool   `gorm:"default:false"`
+	TwoFASecret      string `gorm:"type:varchar(100)"`
+	// Locale is the preferred language for API messages and emails, e.g. "id".
+	Locale          string  `gorm:"type:varchar(10)"`
+	Roles           []*Role `gorm:"many2many:user_roles;"`
+	UserPermissions []*UserPermission
+	// ActiveOrganizationID is the tenant used when a request names none.
+	ActiveOrganizationID *string `gorm:"type:char(26)"`
+	Memberships          []*Membership
+	// Groups the user was added to directly, linked to their parent groups.
+	// Filled by the principal loader and narrowed to the request's tenant.
+	Groups []*Group `gorm:"-"`
+	// TenantRoles are the roles of the user's membership in the request's
+	// organization. They are set only on routes that act inside it, so
+	// per-org roles never grant anything outside their organization.
+	TenantRoles []*Role `gorm:"-"`
 }
 
 // Helper methods for Role & Permission checks
@@ -29,17 +43,133 @@ func (u *User) HasRole(roleName string) bool {
 	return false
 }
 
+// HasImpliedRole is like HasRole but also accepts roles inherited through the role hierarchy.
+func (u *User) HasImpliedRole(roleName string) bool {
+	for _, role := range u.grantedRoles() {
+		if role.Implies(roleName) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission checks the user's direct permissions and the permissions of
+// their roles and groups, including inherited ones. An explicit deny
+// overrides any allow.
 func (u *User) HasPermission(permissionName string) bool {
-	for _, role := range u.Roles {
-		for _, permission := range role.Permissions {
-			if permission.Name == permissionName {
-				return true
-			}
+	if u.IsDenied(permissionName) {
+		return false
+	}
+
+	for _, up := range u.UserPermissions {
+		if up.Effect == PermissionEffectAllow && up.Matches(permissionName) {
+			return true
+		}
+	}
+
+	for _, role := range u.grantedRoles() {
+		if role.GrantsPermission(permissionName) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDenied reports whether a direct deny entry blocks the permission.
+func (u *User) IsDenied(permissionName string) bool {
+	for _, up := range u.UserPermissions {
+		if up.Effect == PermissionEffectDeny && up.Matches(permissionName) {
+			return true
 		}
 	}
 	return false
 }
 
+// EffectivePermissions returns every permission granted through the user's
+// roles, groups and direct allows, leaving out those blocked by a deny.
+func (u *User) EffectivePermissions() []*Permission {
+	seen := map[string]bool{}
+	var permissions []*Permission
+	add := func(permission *Permission) {
+		if permission == nil || seen[permission.Name] || u.IsDenied(permission.Name) {
+			return
+		}
+		seen[permission.Name] = true
+		permissions = append(permissions, permission)
+	}
+
+	for _, up := range u.UserPermissions {
+		if up.Effect == PermissionEffectAllow {
+			add(up.Permission)
+		}
+	}
+	for _, role := range u.grantedRoles() {
+		for _, permission := range role.EffectivePermissions() {
+			add(permission)
+		}
+	}
+	return permissions
+}
+
+// DeniedPermissions returns the permissions explicitly denied to the user.
+func (u *User) DeniedPermissions() []*Permission {
+	var permissions []*Permission
+	for _, up := range u.UserPermissions {
+		if up.Effect == PermissionEffectDeny && up.Permission != nil {
+			permissions = append(permissions, up.Permission)
+		}
+	}
+	return permissions
+}
+
+// MembershipFor returns the user's active membership in the organization named
+// by ID or slug, or nil.
+func (u *User) MembershipFor(organizationRef string) *Membership {
+	for _, membership := range u.Memberships {
+		if membership.IsActive() && membership.Matches(organizationRef) {
+			return membership
+		}
+	}
+	return nil
+}
+
+// GroupRoles returns the roles the user gets through their groups, including
+// roles of parent groups.
+func (u *User) GroupRoles() []*Role {
+	seen := map[string]bool{}
+	var roles []*Role
+	for _, group := range u.Groups {
+		for _, role := range group.EffectiveRoles() {
+			if !seen[role.ID] {
+				seen[role.ID] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// ScopeGroups keeps only the groups of the given organization. Group grants
+// never apply outside their organization, so "" drops them all.
+func (u *User) ScopeGroups(organizationID string) {
+	scoped := u.Groups[:0]
+	for _, group := range u.Groups {
+		if organizationID != "" && group.OrganizationID == organizationID {
+			scoped = append(scoped, group)
+		}
+	}
+	u.Groups = scoped
+}
+
+// grantedRoles is the user's own roles followed by their tenant and group roles.
+func (u *User) grantedRoles() []*Role {
+	if len(u.Groups) == 0 && len(u.TenantRoles) == 0 {
+		return u.Roles
+	}
+	roles := append(append([]*Role{}, u.Roles...), u.TenantRoles...)
+	return append(roles, u.GroupRoles()...)
+}
+
 func (u *User) AssignRole(role *Role) {
 	u.Roles = append(u.Roles, role)
 }
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	PermissionEffectAllow = "allow"
+	PermissionEffectDeny  = "deny"
+)
+
+// UserPermission grants or denies one permission to a single user on top of
+// what their roles grant. A matching deny always overrides any allow.
+type UserPermission struct {
+	UserID       string      `gorm:"primaryKey;type:char(26)"`
+	PermissionID string      `gorm:"primaryKey;type:char(26)"`
+	Effect       string      `gorm:"type:varchar(10);not null;default:allow"`
+	Permission   *Permission `gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time
+}
+
+// Matches reports whether the entry's permission covers permissionName, wildcards included.
+func (up *UserPermission) Matches(permissionName string) bool {
+	return up.Permission != nil && MatchPermission(up.Permission.Name, permissionName)
+}
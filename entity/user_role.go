@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRole is the user_roles join table. An assignment may be scheduled
+// (StartsAt) or temporary (ExpiresAt); outside that window it grants nothing.
+type UserRole struct {
+	UserID           string  `gorm:"primaryKey;type:char(26)"`
+	RoleID           string  `gorm:"primaryKey;type:char(26)"`
+	GrantedBy        *string `gorm:"type:char(26)"`
+	GrantedAt        time.Time
+	StartsAt         *time.Time
+	ExpiresAt        *time.Time `gorm:"index"`
+	ExpiryNotifiedAt *time.Time
+}
+
+// ActiveAt reports whether the assignment is in effect at t.
+func (ur *UserRole) ActiveAt(t time.Time) bool {
+	if ur.StartsAt != nil && t.Before(*ur.StartsAt) {
+		return false
+	}
+	return ur.ExpiresAt == nil || t.Before(*ur.ExpiresAt)
+}
+
+// SetupJoinTables registers the custom join models. It must run once per
+// connection before any association or preload touches those tables.
+func SetupJoinTables(db *gorm.DB) error {
+	return db.SetupJoinTable(&User{}, "Roles", &UserRole{})
+}
@@ -0,0 +1,51 @@
+package events
+
+import "sync"
+
+// Event names published when data behind a cached principal changes.
+const (
+	// UserChanged concerns a single user, named by Event.UserID.
+	UserChanged = "user.changed"
+	// RBACChanged concerns roles or permissions and may affect any user.
+	RBACChanged = "rbac.changed"
+)
+
+type Event struct {
+	Name   string
+	UserID string
+}
+
+type Handler func(event Event)
+
+// Bus is an in-process, synchronous event bus: Publish returns once every
+// handler has run, so a cache is invalidated before the response is sent.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish runs the handlers subscribed to the event. A nil bus drops the event.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers[event.Name]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,145 @@
+// Package i18n translates API messages and emails. English is the source
+// language: messages are written in English in the code and used as keys
+// into the catalogs of the other locales, so a missing translation falls
+// back to the English text.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Supported locales.
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// contextKey holds the request locale once it is known, e.g. from the
+// user's preference.
+const contextKey = "locale"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// catalogs maps locale to English message to translation.
+var catalogs = map[string]map[string]string{English: {}}
+
+var defaultLocale atomic.Value
+
+func init() {
+	defaultLocale.Store(English)
+
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := files.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+}
+
+// Supported reports whether the locale has a catalog.
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Locales lists the supported locales, sorted.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetDefault sets the locale used when neither the user nor the request
+// picks a supported one.
+func SetDefault(locale string) error {
+	if !Supported(locale) {
+		return fmt.Errorf("i18n: unsupported locale %q", locale)
+	}
+	defaultLocale.Store(locale)
+	return nil
+}
+
+// Default is the fallback locale.
+func Default() string {
+	return defaultLocale.Load().(string)
+}
+
+// T translates an English message. With args the translation is used as a
+// fmt format. Unknown locales use the default locale.
+func T(locale, message string, args ...interface{}) string {
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = catalogs[Default()]
+	}
+	if translated, ok := catalog[message]; ok && translated != "" {
+		message = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Negotiate picks the supported locale the client prefers most from an
+// Accept-Language header such as "id-ID,id;q=0.9,en;q=0.8".
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := Default(), 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !Supported(primary) {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// Ties keep the earlier entry
+		if q > bestQ {
+			best, bestQ = primary, q
+		}
+	}
+	return best
+}
+
+// SetLocale fixes the locale for the rest of the request.
+func SetLocale(c *gin.Context, locale string) {
+	if Supported(locale) {
+		c.Set(contextKey, locale)
+	}
+}
+
+// Locale is the request's locale: the one set with SetLocale, otherwise
+// negotiated from Accept-Language.
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(contextKey); locale != "" {
+		return locale
+	}
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"log/slog"
@@ -9,14 +11,18 @@ import (
 
 	"gopkg.in/natefinch/lumberjack.v2"
 
+	"golang-backend/cache"
 	"golang-backend/config"
 	"golang-backend/controller"
+	"golang-backend/events"
+	"golang-backend/i18n"
 	"golang-backend/middleware"
 	"golang-backend/migrations"
 	"golang-backend/repository"
 	"golang-backend/routes"
 	"golang-backend/service"
 	"golang-backend/utils"
+	"golang-backend/validation"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,47 +36,86 @@ import (
 // @in header
 // @name Authorization
 func main() {
-	// 1. Load environment variables
-	config.LoadEnv()
+	// 1. Load configuration: defaults, YAML file, .env/environment, flags
+	migrate := flag.Bool("migrate", false, "Apply pending database migrations before starting (see cmd/migrate)")
+	seed := flag.Bool("seed", false, "Run database seeder")
+	syncPermissions := flag.Bool("sync-permissions", false, "Create permissions required by routes and report orphaned ones")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration with secrets redacted and exit")
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Parse()
 
-	// Setup logging with rotation (lumberjack)
-	logFile := &lumberjack.Logger{
-		Filename:   "logs/server.log",
-		MaxSize:    10, // megabytes
-		MaxBackups: 5,
-		MaxAge:     30,   // days
-		Compress:   true, // disabled by default
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	if *printConfig {
+		fmt.Print(cfg)
+		return
 	}
 
-	// Set gin writer to both file and console
-	gin.DefaultWriter = io.MultiWriter(logFile, os.Stdout)
-	log.SetOutput(gin.DefaultWriter)
-
-	// Setup slog for structured logging (JSON)
-	logger := slog.New(slog.NewJSONHandler(gin.DefaultWriter, nil))
-	slog.SetDefault(logger)
+	// Rate limits, CORS origins, log level, feature flags and the policy
+	// decision log reload on SIGHUP or when a config file changes
+	live := config.NewLive(cfg, loader)
+	setupLogging(live)
+	stopWatch := live.Watch(cfg.Server.ConfigWatchInterval)
+	defer stopWatch()
 
 	// 2. Initialize database
-	db := config.InitDB()
-
-	// 3. Run Migrations (Optional via flag)
-	migrate := flag.Bool("migrate", false, "Run database migrations")
-	seed := flag.Bool("seed", false, "Run database seeder")
-	flag.Parse()
+	db, err := config.OpenDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
 
+	// 3. Run Migrations (Optional via flag) on a connection of their own
 	if *migrate {
-		migrations.RunMigrations(db)
+		if err := runMigrations(cfg.Database); err != nil {
+			log.Fatalf("Failed to migrate: %v", err)
+		}
 	}
 
+	// Lists and searches read from DB_REPLICAS when set
+	stopReplicas, err := config.UseReplicas(db, cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to open read replicas: %v", err)
+	}
+	defer stopReplicas()
+
 	// 4. Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	// Runs repository calls of a service as one transaction
+	txManager := repository.NewTransactionManager(db)
+
+	if err := i18n.SetDefault(cfg.Locale.Default); err != nil {
+		log.Fatalf("Invalid DEFAULT_LOCALE: %v", err)
+	}
+
+	// Custom binding rules; unique_email looks users up by email
+	if err := validation.Register(userRepo); err != nil {
+		log.Fatalf("Failed to register validation rules: %v", err)
+	}
 
 	// 4. Initialize services
-	userService := service.NewUserService(userRepo)
+	bus := events.NewBus()
+	tokens := utils.NewTokenManager(cfg.JWT.Secret, cfg.JWT.Expiration())
+	mailer := utils.NewMailer(cfg.SMTP)
+	auditService := service.NewAuditService(auditRepo)
+	userService := service.NewUserService(userRepo, roleRepo, txManager, bus, auditService, tokens, mailer, live)
+	rbacService := service.NewRBACService(roleRepo, txManager, bus, mailer)
+	orgService := service.NewOrganizationService(orgRepo, userRepo, roleRepo, txManager, bus, tokens, mailer)
+	groupService := service.NewGroupService(groupRepo, orgRepo, roleRepo, txManager, bus)
+	principals := newPrincipalLoader(rbacService, bus, cfg)
 
 	// 5. Initialize controllers
 	userCtrl := controller.NewUserController(userService)
-	roleCtrl := controller.NewRoleController(db)
+	roleCtrl := controller.NewRoleController(rbacService, auditService)
+	orgCtrl := controller.NewOrganizationController(orgService)
+	groupCtrl := controller.NewGroupController(groupService)
+	auditCtrl := controller.NewAuditController(auditService)
+	configCtrl := controller.NewConfigController(live)
 
 	// Run Seeder
 	if *seed {
@@ -79,42 +124,130 @@ func main() {
 		utils.SeedUsers(db)
 	}
 
+	// Remove expired role assignments and warn holders before expiry
+	stopRoleExpiry := service.StartRoleExpiryWorker(rbacService, cfg.RBAC.RoleSweepInterval, cfg.RBAC.RoleExpiryNotice)
+	defer stopRoleExpiry()
+
+	// Resource policies checked by controllers before touching a record
+	middleware.RegisterUserPolicies()
+	middleware.SetPolicyDecisionLog(cfg.RBAC.PolicyDecisionLog)
+	live.OnChange(func(_, current *config.Config) {
+		middleware.SetPolicyDecisionLog(current.RBAC.PolicyDecisionLog)
+	})
+
 	// 6. Initialize Gin router
 	app := gin.Default()
 
 	// 7. Apply global middleware
+	app.Use(middleware.RequestIDMiddleware())
+	app.Use(middleware.TimeoutMiddleware(live))
 	app.Use(middleware.LoggerMiddleware())
-	app.Use(middleware.CORSMiddleware())
+	app.Use(middleware.CORSMiddleware(live))
 	app.Use(middleware.ErrorHandlerMiddleware())
-	app.Use(middleware.RateLimiterMiddleware())
+	app.Use(middleware.RateLimiterMiddleware(live))
 
 	// 8. Setup routes
-	routes.SetupRoutes(app, userCtrl, roleCtrl)
+	routeRegistry := middleware.NewRouteRegistry()
+	routeCtrl := controller.NewRouteController(app, routeRegistry)
+	routes.SetupRoutes(app, routeRegistry, userCtrl, roleCtrl, routeCtrl, orgCtrl, groupCtrl, auditCtrl, configCtrl, principals, tokens, cfg.Tenant.BaseDomain)
 
-	// 9. Health check endpoint
-	app.GET("/health", func(c *gin.Context) {
-		sqlDB, err := db.DB()
+	if *syncPermissions {
+		report, err := rbacService.SyncPermissions(context.Background(), routeRegistry.Permissions())
 		if err != nil {
-			utils.ErrorResponse(c, "Database connection failed", 500, nil)
-			return
+			log.Fatalf("Failed to sync route permissions: %v", err)
 		}
+		slog.Info("Route permissions synced", "created", report.Created, "orphaned", report.Orphaned)
+	}
+
+	// 9. Health check and pool metrics endpoints; metrics listen on
+	// METRICS_ADDR only, never on the public port
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database instance: %v", err)
+	}
+	healthCtrl := controller.NewHealthController(sqlDB)
+	app.GET("/health", healthCtrl.Health)
+	if cfg.Server.MetricsAddr != "" {
+		go serveMetrics(cfg.Server.MetricsAddr, healthCtrl)
+	}
+
+	// 10. Start server
+	log.Printf("🚀 Server starting on port %d", cfg.Server.Port)
+	if err := app.Run(cfg.Server.Addr()); err != nil {
+		log.Fatalf("Server failed to start: %v", err)
+	}
+}
 
-		if err := sqlDB.Ping(); err != nil {
-			utils.ErrorResponse(c, "Database ping failed", 500, nil)
-			return
+// serveMetrics serves /metrics on a listener of its own, so scraping needs
+// access to METRICS_ADDR rather than to the API.
+func serveMetrics(addr string, healthCtrl *controller.HealthController) {
+	metrics := gin.New()
+	metrics.Use(gin.Recovery())
+	metrics.GET("/metrics", healthCtrl.Metrics)
+
+	log.Printf("Metrics listening on %s", addr)
+	if err := metrics.Run(addr); err != nil {
+		log.Fatalf("Metrics server failed to start: %v", err)
+	}
+}
+
+// setupLogging sends gin, log and slog output to stdout and, when set, the
+// rotated log file. The slog level follows config reloads.
+func setupLogging(live *config.Live) {
+	cfg := live.Get().Log
+	var out io.Writer = os.Stdout
+	if cfg.File != "" {
+		logFile := &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    10, // megabytes
+			MaxBackups: 5,
+			MaxAge:     30,   // days
+			Compress:   true, // disabled by default
 		}
+		out = io.MultiWriter(logFile, os.Stdout)
+	}
 
-		utils.SuccessResponse(c, "API is running and DB is connected", nil)
+	// Set gin writer to both file and console
+	gin.DefaultWriter = out
+	log.SetOutput(out)
+
+	// Setup slog for structured logging (JSON)
+	level := new(slog.LevelVar)
+	level.Set(cfg.SlogLevel())
+	live.OnChange(func(_, current *config.Config) {
+		level.Set(current.Log.SlogLevel())
 	})
 
-	// 10. Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	logger := slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level}))
+	slog.SetDefault(logger)
+}
+
+// newPrincipalLoader wraps the RBAC service in the configured principal cache.
+func newPrincipalLoader(rbacService service.RBACService, bus *events.Bus, cfg *config.Config) middleware.PrincipalLoader {
+	var store cache.Store
+	switch cfg.PrincipalCache.Backend {
+	case "none":
+		return rbacService
+	case "redis":
+		store = cache.NewRedisStore(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB)
+	default:
+		store = cache.NewMemoryStore(cfg.PrincipalCache.Size)
 	}
 
-	log.Printf("🚀 Server starting on port %s", port)
-	if err := app.Run(":" + port); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	return service.NewPrincipalCache(rbacService, store, cfg.PrincipalCache.TTL, bus)
+}
+
+// runMigrations applies pending migrations over a connection opened for them, so
+// the application pool keeps rejecting multi-statement queries.
+func runMigrations(cfg config.DatabaseConfig) error {
+	db, err := config.OpenMigrationDatabase(cfg)
+	if err != nil {
+		return err
 	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	_, err = migrations.NewMigrator(db).Up(0)
+	return err
 }
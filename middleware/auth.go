@@ -1,18 +1,26 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
-	"golang-backend/config"
 	"golang-backend/entity"
+	"golang-backend/i18n"
+	"golang-backend/reqctx"
 	"golang-backend/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// PrincipalLoader loads the authenticated user together with the roles and
+// permissions the authorization middleware checks against.
+type PrincipalLoader interface {
+	LoadPrincipal(ctx context.Context, userID string) (*entity.User, error)
+}
+
+func AuthMiddleware(principals PrincipalLoader, tokens *utils.TokenManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -29,7 +37,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := parts[1]
-		token, err := utils.ValidateToken(tokenString)
+		token, err := tokens.ValidateToken(tokenString)
 		if err != nil || !token.Valid {
 			utils.ErrorResponse(c, "Unauthorized", http.StatusUnauthorized, "Invalid or expired token")
 			c.Abort()
@@ -50,17 +58,23 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("user_id", userID)
+		if organizationID, ok := claims["org_id"].(string); ok {
+			c.Set("token_organization_id", organizationID)
+		}
 
-		// Fetch user with roles and permissions
-		var user entity.User
-		if err := config.DB.Preload("Roles.Permissions").First(&user, "id = ?", userID).Error; err != nil {
+		// Fetch user with roles, inherited roles and permissions
+		user, err := principals.LoadPrincipal(c.Request.Context(), userID)
+		if err != nil {
 			utils.ErrorResponse(c, "Unauthorized", http.StatusUnauthorized, "User not found")
 			c.Abort()
 			return
 		}
 
-		c.Set("currentUser", &user)
+		c.Request = c.Request.WithContext(reqctx.WithPrincipal(c.Request.Context(), user))
+		// The user's preference wins over Accept-Language
+		if user.Locale != "" {
+			i18n.SetLocale(c, user.Locale)
+		}
 		c.Next()
 	}
 }
@@ -3,7 +3,7 @@ package middleware
 import (
 	"net/http"
 
-	"golang-backend/entity"
+	"golang-backend/reqctx"
 	"golang-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -11,20 +11,17 @@ import (
 
 func RoleAuthMiddleware(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		currentUser, exists := c.Get("currentUser")
-		if !exists {
+		user := reqctx.Principal(c.Request.Context())
+		if user == nil {
 			utils.ErrorResponse(c, "Unauthorized", http.StatusUnauthorized, nil)
 			c.Abort()
 			return
 		}
 
-		user := currentUser.(*entity.User)
-
-		// If user has 'admin' role, they can probably do anything, but let's be strict for now or allow it
-		// For now, checks if user has ANY of the passed roles
+		// Checks if user has ANY of the passed roles, directly or through role inheritance
 		hasRole := false
 		for _, role := range roles {
-			if user.HasRole(role) {
+			if user.HasImpliedRole(role) {
 				hasRole = true
 				break
 			}
@@ -42,15 +39,13 @@ func RoleAuthMiddleware(roles ...string) gin.HandlerFunc {
 
 func PermissionAuthMiddleware(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		currentUser, exists := c.Get("currentUser")
-		if !exists {
+		user := reqctx.Principal(c.Request.Context())
+		if user == nil {
 			utils.ErrorResponse(c, "Unauthorized", http.StatusUnauthorized, nil)
 			c.Abort()
 			return
 		}
 
-		user := currentUser.(*entity.User)
-
 		// Check if user has ANY of the required permissions
 		hasPermission := false
 		for _, perm := range permissions {
@@ -1,18 +1,42 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"golang-backend/apperror"
+	"golang-backend/config"
+	"golang-backend/i18n"
+	"golang-backend/utils"
+	"golang-backend/validation"
+
+	"github.com/gin-gonic/gin"
+)
 
 func LoggerMiddleware() gin.HandlerFunc {
 	return gin.Logger()
 }
 
-func CORSMiddleware() gin.HandlerFunc {
+// CORSMiddleware echoes the request Origin with credentials allowed only for
+// origins listed by name. Origins let in by "*" are answered with "*", which
+// browsers never pair with credentials.
+func CORSMiddleware(live *config.Live) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Add("Vary", "Origin")
+		if origin := c.GetHeader("Origin"); origin != "" {
+			switch allowed, credentials := live.Get().CORS.Allows(origin); {
+			case credentials:
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			case allowed:
+				c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+		}
 		c.Writer.Header().Set("Access-Control-Allow-Headers",
-			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -23,9 +47,84 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
+// TimeoutMiddleware sets the REQUEST_TIMEOUT deadline on the request
+// context. Database work of the request stops once it passes and the
+// handler fails with apperror.Timeout. The timeout follows config reloads.
+func TimeoutMiddleware(live *config.Live) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := live.Get().Server.RequestTimeout
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// ErrorHandlerMiddleware turns errors added with c.Error and panics into the
+// standard response envelope. Handlers only call c.Error(err) and return;
+// the error is mapped with apperror.From so internal causes are logged but
+// never sent to the client. Binding errors are answered per JSON field in
+// the request's locale.
 func ErrorHandlerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				slog.Error("Panic while handling request",
+					"panic", recovered,
+					"method", c.Request.Method,
+					"path", c.Request.URL.Path,
+					"request_id", RequestID(c),
+					"stack", string(debug.Stack()),
+				)
+				if !c.Writer.Written() {
+					utils.ErrorCodeResponse(c, "Internal server error", string(apperror.Internal), http.StatusInternalServerError, nil)
+				}
+				c.Abort()
+			}
+		}()
+
 		c.Next()
-		// Check if there are errors and handle them if needed
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperror.From(c.Errors.Last().Err)
+		status := appErr.Status()
+		if appErr.Code == apperror.Timeout {
+			slog.Warn("Request timed out",
+				"error", appErr.Error(),
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"request_id", RequestID(c),
+			)
+		} else if status >= http.StatusInternalServerError {
+			slog.Error("Request failed",
+				"error", appErr.Error(),
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"request_id", RequestID(c),
+			)
+		} else if appErr.Err != nil {
+			slog.Debug("Request rejected",
+				"code", appErr.Code,
+				"error", appErr.Err.Error(),
+				"request_id", RequestID(c),
+			)
+		}
+
+		details := appErr.Details
+		if appErr.Code == apperror.Validation {
+			if fields := validation.FieldErrors(appErr, i18n.Locale(c)); fields != nil {
+				details = fields
+			}
+		}
+
+		utils.ErrorCodeResponse(c, appErr.Message, string(appErr.Code), status, details)
 	}
 }
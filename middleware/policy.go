@@ -1,9 +1,14 @@
 package middleware
 
 import (
-	"errors"
+	"fmt"
+	"golang-backend/apperror"
 	"golang-backend/entity"
+	"golang-backend/reqctx"
+	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,34 +16,71 @@ import (
 // Policy helpers to mimic Laravel's Authorization policies
 // In Go, we can't easily use Traits, but we can use helper functions.
 
+// PolicyFunc decides whether the user may perform an action on a loaded
+// resource, for rules a flat permission cannot express such as "users may view
+// their own record". resource is nil when the action targets a whole collection.
+type PolicyFunc func(ctx *gin.Context, user *entity.User, resource interface{}) bool
+
+var (
+	policyMu     sync.RWMutex
+	policies     = map[string][]PolicyFunc{}
+	logDecisions atomic.Bool
+)
+
+// RegisterPolicy adds a policy for the module/action pair. Several policies may
+// share a pair; any one of them allowing is enough.
+func RegisterPolicy(module, action string, policy PolicyFunc) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+
+	key := entity.PermissionName(module, action)
+	policies[key] = append(policies[key], policy)
+}
+
+// SetPolicyDecisionLog turns logging of every authorization decision on or off.
+func SetPolicyDecisionLog(enabled bool) {
+	logDecisions.Store(enabled)
+}
+
+// Authorize checks the "module:action" permission, e.g. Authorize(ctx, "read", "users")
+// requires "users:read", which is also satisfied by "users:*" or "*:*".
 func Authorize(ctx *gin.Context, action, module string) error {
-	currentUser, exists := ctx.Get("currentUser")
-	if !exists {
-		return errors.New("unauthorized")
-	}
+	return AuthorizeResource(ctx, action, module, nil)
+}
 
-	user, ok := currentUser.(*entity.User)
-	if !ok {
-		return errors.New("invalid user context")
+// AuthorizeResource allows the action when the user holds the "module:action"
+// permission or a policy registered for it accepts the resource. A direct deny
+// of the permission overrides both. Controllers call it after loading the
+// resource and before changing it.
+func AuthorizeResource(ctx *gin.Context, action, module string, resource interface{}) error {
+	user := reqctx.Principal(ctx.Request.Context())
+	if user == nil {
+		return apperror.Unauthorized.New("Unauthorized")
 	}
 
-	permissionName := action + "_" + module
-	if !user.HasPermission(permissionName) {
-		return errors.New("forbidden: insufficient permissions")
+	if !CanResource(ctx, user, action, module, resource) {
+		return apperror.Forbidden.New("Forbidden: insufficient permissions")
 	}
 
 	return nil
 }
 
+// Can evaluates the action for any loaded principal, not only the current user,
+// with the same rules as Authorize.
+func Can(ctx *gin.Context, user *entity.User, action, module string) bool {
+	return CanResource(ctx, user, action, module, nil)
+}
+
+// CanResource is Can for a loaded resource, with the same rules as AuthorizeResource.
+func CanResource(ctx *gin.Context, user *entity.User, action, module string, resource interface{}) bool {
+	permission := entity.PermissionName(module, action)
+	allowed, reason := decide(ctx, user, permission, resource)
+	logDecision(ctx, user, permission, resource, allowed, reason)
+	return allowed
+}
+
 func AuthorizeRead(ctx *gin.Context, module string) error {
-	// Usually maps to 'view_{module}' or 'read_{module}'
-	// Let's assume 'view' based on common conventions or 'read'
-	// The user example had authorizeRead. Let's use 'view' as a common standard or 'read'.
-	// If the user's PHP system uses 'admin_unit' module, it likely has 'view_admin_unit'.
-	// I'll stick to 'view' or 'read'. Let's use 'view' for UI friendliness or 'read' for CRUD.
-	// Let's genericize to just pass the action. But to match PHP 'authorizeRead', I'll make a choice.
-	// PHP policy usually maps method to permission.
-	return Authorize(ctx, "view", module)
+	return Authorize(ctx, "read", module)
 }
 
 func AuthorizeCreate(ctx *gin.Context, module string) error {
@@ -46,13 +88,25 @@ func AuthorizeCreate(ctx *gin.Context, module string) error {
 }
 
 func AuthorizeEdit(ctx *gin.Context, module string) error {
-	return Authorize(ctx, "edit", module)
+	return Authorize(ctx, "update", module)
 }
 
 func AuthorizeDelete(ctx *gin.Context, module string) error {
 	return Authorize(ctx, "delete", module)
 }
 
+func AuthorizeReadResource(ctx *gin.Context, module string, resource interface{}) error {
+	return AuthorizeResource(ctx, "read", module, resource)
+}
+
+func AuthorizeEditResource(ctx *gin.Context, module string, resource interface{}) error {
+	return AuthorizeResource(ctx, "update", module, resource)
+}
+
+func AuthorizeDeleteResource(ctx *gin.Context, module string, resource interface{}) error {
+	return AuthorizeResource(ctx, "delete", module, resource)
+}
+
 // Helper to handle the error response automatically if desired, similar to Abort API
 func EnsurePermission(ctx *gin.Context, action, module string) bool {
 	if err := Authorize(ctx, action, module); err != nil {
@@ -65,3 +119,39 @@ func EnsurePermission(ctx *gin.Context, action, module string) bool {
 	}
 	return true
 }
+
+func decide(ctx *gin.Context, user *entity.User, permission string, resource interface{}) (bool, string) {
+	if user.IsDenied(permission) {
+		return false, "explicit deny"
+	}
+	if user.HasPermission(permission) {
+		return true, "permission"
+	}
+
+	policyMu.RLock()
+	registered := policies[permission]
+	policyMu.RUnlock()
+
+	for _, policy := range registered {
+		if policy(ctx, user, resource) {
+			return true, "policy"
+		}
+	}
+
+	return false, "no matching permission or policy"
+}
+
+func logDecision(ctx *gin.Context, user *entity.User, permission string, resource interface{}, allowed bool, reason string) {
+	if !logDecisions.Load() {
+		return
+	}
+
+	slog.Info("Authorization decision",
+		"user_id", user.ID,
+		"permission", permission,
+		"resource", fmt.Sprintf("%T", resource),
+		"route", ctx.FullPath(),
+		"allowed", allowed,
+		"reason", reason,
+	)
+}
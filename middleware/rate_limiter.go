@@ -3,7 +3,10 @@ package middleware
 import (
 	"net/http"
 	"sync"
+	"sync/atomic"
 
+	"golang-backend/config"
+	"golang-backend/reqctx"
 	"golang-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -64,25 +67,44 @@ func (u *UserRateLimiter) GetLimiter(userID string) *rate.Limiter {
 	return limiter.(*rate.Limiter)
 }
 
-func RateLimiterMiddleware() gin.HandlerFunc {
-	// IP Limit: 5 requests per second, burst: 10
-	ipLimiter := NewIPRateLimiter(5, 10)
-	// User Limit: 10 requests per second, burst: 15
-	userLimiter := NewUserRateLimiter(10, 15)
+// rateLimiters are the buckets for one rate limit configuration.
+type rateLimiters struct {
+	ip   *IPRateLimiter
+	user *UserRateLimiter
+}
+
+func newRateLimiters(cfg config.RateLimitConfig) *rateLimiters {
+	return &rateLimiters{
+		ip:   NewIPRateLimiter(rate.Limit(cfg.IPRate), cfg.IPBurst),
+		user: NewUserRateLimiter(rate.Limit(cfg.UserRate), cfg.UserBurst),
+	}
+}
+
+// RateLimiterMiddleware limits requests per client IP and per user. When a
+// reload changes the limits, all buckets start over with the new values.
+func RateLimiterMiddleware(live *config.Live) gin.HandlerFunc {
+	var limiters atomic.Pointer[rateLimiters]
+	limiters.Store(newRateLimiters(live.Get().RateLimit))
+	live.OnChange(func(previous, current *config.Config) {
+		if previous.RateLimit != current.RateLimit {
+			limiters.Store(newRateLimiters(current.RateLimit))
+		}
+	})
 
 	return func(c *gin.Context) {
+		active := limiters.Load()
+
 		// Check IP Limit
 		ip := c.ClientIP()
-		if !ipLimiter.GetLimiter(ip).Allow() {
+		if !active.ip.GetLimiter(ip).Allow() {
 			utils.ErrorResponse(c, "Too Many Requests", http.StatusTooManyRequests, "IP rate limit exceeded")
 			c.Abort()
 			return
 		}
 
 		// Check User Limit if authenticated
-		userID, exists := c.Get("user_id")
-		if exists {
-			if !userLimiter.GetLimiter(userID.(string)).Allow() {
+		if userID := reqctx.PrincipalID(c.Request.Context()); userID != "" {
+			if !active.user.GetLimiter(userID).Allow() {
 				utils.ErrorResponse(c, "Too Many Requests", http.StatusTooManyRequests, "User rate limit exceeded")
 				c.Abort()
 				return
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/rand"
+
+	"golang-backend/reqctx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware keeps the caller's X-Request-ID when it looks sane and
+// generates one otherwise. The ID is echoed in the response and stored,
+// with the client address, in the request context for logs and audit
+// records.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = ulid.MustNew(ulid.Now(), rand.Reader).String()
+		}
+
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(reqctx.WithRequest(c.Request.Context(), reqctx.Request{
+			ID:        requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+		c.Next()
+	}
+}
+
+// RequestID returns the ID assigned by RequestIDMiddleware.
+func RequestID(c *gin.Context) string {
+	return reqctx.RequestID(c.Request.Context())
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoutePermission is the permission a route requires.
+type RoutePermission struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Permission string `json:"permission"`
+	// Policy routes check the permission in the handler, where a resource
+	// policy may also grant access, instead of in PermissionAuthMiddleware.
+	Policy bool `json:"policy"`
+}
+
+// RouteRegistry records the permission each route requires, so the
+// permissions table can be synced with the routes instead of kept by hand.
+type RouteRegistry struct {
+	mu     sync.RWMutex
+	routes []RoutePermission
+}
+
+func NewRouteRegistry() *RouteRegistry {
+	return &RouteRegistry{}
+}
+
+// Handle registers the route behind PermissionAuthMiddleware(permission).
+func (r *RouteRegistry) Handle(group *gin.RouterGroup, method, relativePath, permission string, handlers ...gin.HandlerFunc) {
+	r.record(group, method, relativePath, permission, false)
+	group.Handle(method, relativePath, append([]gin.HandlerFunc{PermissionAuthMiddleware(permission)}, handlers...)...)
+}
+
+// HandlePolicy registers a route whose handler authorizes itself with
+// Authorize or AuthorizeResource against the declared permission.
+func (r *RouteRegistry) HandlePolicy(group *gin.RouterGroup, method, relativePath, permission string, handlers ...gin.HandlerFunc) {
+	r.record(group, method, relativePath, permission, true)
+	group.Handle(method, relativePath, handlers...)
+}
+
+// Routes returns the registered routes sorted by path and method.
+func (r *RouteRegistry) Routes() []RoutePermission {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := append([]RoutePermission(nil), r.routes...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Lookup returns the registration for a route as gin reports it, e.g. "/api/users/:id".
+func (r *RouteRegistry) Lookup(method, fullPath string) (RoutePermission, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, route := range r.routes {
+		if route.Method == method && route.Path == fullPath {
+			return route, true
+		}
+	}
+	return RoutePermission{}, false
+}
+
+// Permissions returns every distinct permission required by a route, sorted.
+func (r *RouteRegistry) Permissions() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := map[string]bool{}
+	var permissions []string
+	for _, route := range r.routes {
+		if !seen[route.Permission] {
+			seen[route.Permission] = true
+			permissions = append(permissions, route.Permission)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+func (r *RouteRegistry) record(group *gin.RouterGroup, method, relativePath, permission string, policy bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.routes = append(r.routes, RoutePermission{
+		Method:     method,
+		Path:       path.Join(group.BasePath(), relativePath),
+		Permission: permission,
+		Policy:     policy,
+	})
+}
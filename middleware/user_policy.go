@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"golang-backend/apperror"
+	"golang-backend/dto"
+	"golang-backend/entity"
+	"golang-backend/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterUserPolicies installs the resource policies for the users module.
+func RegisterUserPolicies() {
+	RegisterPolicy("users", "read", ownsUserRecord)
+	RegisterPolicy("users", "update", managesUserDepartment)
+}
+
+// UserUpdate is the resource of a users:update check: the user being edited,
+// loaded with their roles, and the changes asked for.
+type UserUpdate struct {
+	User    *entity.User
+	Changes dto.UpdateUserRequest
+}
+
+// MovesDepartment reports whether the update puts the user in another department.
+func (u *UserUpdate) MovesDepartment() bool {
+	return u.Changes.Department != nil && *u.Changes.Department != u.User.Department
+}
+
+// AuthorizeDepartmentChange lets only global grants move a user to another
+// department. The department feeds the manager policy in every organization,
+// so users:update held through per-org or group roles is not enough.
+func AuthorizeDepartmentChange(ctx *gin.Context, update *UserUpdate) error {
+	if !update.MovesDepartment() {
+		return nil
+	}
+
+	user := reqctx.Principal(ctx.Request.Context())
+	if user == nil {
+		return apperror.Unauthorized.New("Unauthorized")
+	}
+
+	global := *user
+	global.Groups, global.TenantRoles = nil, nil
+	if !global.HasPermission("users:update") {
+		return apperror.Forbidden.New("Forbidden: insufficient permissions")
+	}
+	return nil
+}
+
+// ownsUserRecord lets a user view their own record.
+func ownsUserRecord(_ *gin.Context, user *entity.User, resource interface{}) bool {
+	target, ok := resource.(*entity.User)
+	return ok && target.ID == user.ID
+}
+
+// managesUserDepartment lets a manager edit users in their own department,
+// except users ranked above manager. Moving a user to another department,
+// the manager included, still needs users:update.
+func managesUserDepartment(_ *gin.Context, user *entity.User, resource interface{}) bool {
+	update, ok := resource.(*UserUpdate)
+	if !ok || user.Department == "" || !user.HasImpliedRole(entity.RoleManager) {
+		return false
+	}
+
+	target := update.User
+	if target.Department != user.Department || outranksManager(target) {
+		return false
+	}
+	return !update.MovesDepartment()
+}
+
+// outranksManager reports whether the user holds admin or a role inheriting
+// manager, such as a director.
+func outranksManager(user *entity.User) bool {
+	for _, role := range user.Roles {
+		if role.Implies(entity.RoleAdmin) || (role.Name != entity.RoleManager && role.Implies(entity.RoleManager)) {
+			return true
+		}
+	}
+	return false
+}
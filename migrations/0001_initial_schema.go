@@ -0,0 +1,176 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The initial schema is frozen in the types below, copies of the entities as
+// they were when versioned migrations began. Later changes to the entities
+// need a migration of their own. On a database created before versioned
+// migrations this migration only adds what is missing.
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			err := tx.AutoMigrate(
+				&role{},
+				&permission{},
+				&user{},
+				&userRole{},
+				&userPermission{},
+				&organization{},
+				&membership{},
+				&group{},
+				&groupMember{},
+				&auditLog{},
+			)
+			if err != nil {
+				return err
+			}
+			return migratePermissionNames(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			// One at a time, referencing tables first: SQLite cannot turn
+			// foreign keys off inside the migration's transaction
+			for _, table := range []string{
+				"audit_logs",
+				"group_roles", "group_members", "groups",
+				"membership_roles", "memberships", "organizations",
+				"user_permissions", "user_roles", "users",
+				"role_parents", "role_permissions", "permissions", "roles",
+			} {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// The type names matter: gorm derives table, join column and constraint
+// names from them, so they match those of the entities.
+
+type role struct {
+	ID          string `gorm:"primaryKey;type:char(26)"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `gorm:"type:varchar(100);uniqueIndex;not null"`
+	Permissions []*permission  `gorm:"many2many:role_permissions;"`
+	Parents     []*role        `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID"`
+}
+
+type permission struct {
+	ID        string `gorm:"primaryKey;type:char(26)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	Name      string         `gorm:"type:varchar(100);uniqueIndex;not null"`
+	Roles     []*role        `gorm:"many2many:role_permissions;"`
+}
+
+type user struct {
+	ID                   string `gorm:"primaryKey;type:char(26)"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	DeletedAt            gorm.DeletedAt `gorm:"index"`
+	Name                 string         `gorm:"type:varchar(100);not null"`
+	Email                string         `gorm:"type:varchar(100);uniqueIndex;not null"`
+	Department           string         `gorm:"type:varchar(100);index"`
+	Password             string         `gorm:"not null"`
+	IsVerified           bool           `gorm:"default:false"`
+	VerificationCode     string         `gorm:"type:varchar(6)"`
+	ResetToken           string         `gorm:"type:varchar(6)"`
+	ResetTokenExpiry     time.Time
+	IsTwoFAEnabled       bool    `gorm:"default:false"`
+	TwoFASecret          string  `gorm:"type:varchar(100)"`
+	Locale               string  `gorm:"type:varchar(10)"`
+	Roles                []*role `gorm:"many2many:user_roles;"`
+	UserPermissions      []*userPermission
+	ActiveOrganizationID *string `gorm:"type:char(26)"`
+	Memberships          []*membership
+}
+
+type userRole struct {
+	UserID           string  `gorm:"primaryKey;type:char(26)"`
+	RoleID           string  `gorm:"primaryKey;type:char(26)"`
+	GrantedBy        *string `gorm:"type:char(26)"`
+	GrantedAt        time.Time
+	StartsAt         *time.Time
+	ExpiresAt        *time.Time `gorm:"index"`
+	ExpiryNotifiedAt *time.Time
+}
+
+type userPermission struct {
+	UserID       string      `gorm:"primaryKey;type:char(26)"`
+	PermissionID string      `gorm:"primaryKey;type:char(26)"`
+	Effect       string      `gorm:"type:varchar(10);not null;default:allow"`
+	Permission   *permission `gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time
+}
+
+type organization struct {
+	ID        string `gorm:"primaryKey;type:char(26)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	Name      string         `gorm:"type:varchar(100);not null"`
+	Slug      string         `gorm:"type:varchar(63);uniqueIndex;not null"`
+}
+
+type membership struct {
+	ID             string `gorm:"primaryKey;type:char(26)"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	OrganizationID string         `gorm:"type:char(26);uniqueIndex:idx_membership_org_user;not null"`
+	UserID         string         `gorm:"type:char(26);uniqueIndex:idx_membership_org_user;index;not null"`
+	Status         string         `gorm:"type:varchar(20);default:active;not null"`
+	InvitedBy      *string        `gorm:"type:char(26)"`
+	JoinedAt       *time.Time
+	Organization   *organization `gorm:"constraint:OnDelete:CASCADE"`
+	User           *user         `gorm:"constraint:OnDelete:CASCADE"`
+	Roles          []*role       `gorm:"many2many:membership_roles;"`
+}
+
+type group struct {
+	ID             string `gorm:"primaryKey;type:char(26)"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	OrganizationID string         `gorm:"type:char(26);uniqueIndex:idx_group_org_name;not null"`
+	Name           string         `gorm:"type:varchar(100);uniqueIndex:idx_group_org_name;not null"`
+	Description    string         `gorm:"type:varchar(255)"`
+	ParentID       *string        `gorm:"type:char(26);index"`
+	Parent         *group         `gorm:"constraint:OnDelete:SET NULL"`
+	Organization   *organization  `gorm:"constraint:OnDelete:CASCADE"`
+	Roles          []*role        `gorm:"many2many:group_roles;"`
+}
+
+type groupMember struct {
+	GroupID   string  `gorm:"primaryKey;type:char(26)"`
+	UserID    string  `gorm:"primaryKey;type:char(26);index"`
+	AddedBy   *string `gorm:"type:char(26)"`
+	CreatedAt time.Time
+	Group     *group `gorm:"constraint:OnDelete:CASCADE"`
+	User      *user  `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type auditLog struct {
+	Sequence   int64     `gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt  time.Time `gorm:"index"`
+	ActorID    *string   `gorm:"type:char(26);index"`
+	Action     string    `gorm:"type:varchar(100);index;not null"`
+	TargetType string    `gorm:"type:varchar(50);index:idx_audit_target"`
+	TargetID   string    `gorm:"type:varchar(64);index:idx_audit_target"`
+	Changes    string    `gorm:"type:text"`
+	IP         string    `gorm:"type:varchar(45)"`
+	UserAgent  string    `gorm:"type:varchar(255)"`
+	RequestID  string    `gorm:"type:varchar(64);index"`
+	PrevHash   string    `gorm:"type:char(64);not null"`
+	Hash       string    `gorm:"type:char(64);uniqueIndex;not null"`
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Full-text index for the smart search on users; each database has its own
+// kind. SQLite gets an FTS5 table kept in step by triggers, but only when
+// the driver was built with FTS5 (-tags sqlite_fts5); without it, and on
+// other databases, search falls back to LIKE.
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "users_fulltext_index",
+		Up: func(tx *gorm.DB) error {
+			switch tx.Dialector.Name() {
+			case "postgres":
+				return tx.Exec(`CREATE INDEX IF NOT EXISTS idx_users_fulltext ON users USING GIN (to_tsvector('indonesian', name || ' ' || email))`).Error
+			case "mysql":
+				return tx.Exec("CREATE FULLTEXT INDEX idx_users_fulltext ON users (name, email)").Error
+			case "sqlite":
+				var fts5 bool
+				if err := tx.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5).Error; err != nil {
+					return err
+				}
+				if !fts5 {
+					log.Println("Warning: SQLite was built without FTS5 (-tags sqlite_fts5); user search will use LIKE")
+					return nil
+				}
+				return tx.Exec(`
+CREATE VIRTUAL TABLE IF NOT EXISTS users_fts USING fts5(id UNINDEXED, name, email);
+INSERT INTO users_fts (id, name, email) SELECT id, name, email FROM users;
+CREATE TRIGGER IF NOT EXISTS users_fts_insert AFTER INSERT ON users BEGIN
+	INSERT INTO users_fts (id, name, email) VALUES (new.id, new.name, new.email);
+END;
+CREATE TRIGGER IF NOT EXISTS users_fts_update AFTER UPDATE OF name, email ON users BEGIN
+	UPDATE users_fts SET name = new.name, email = new.email WHERE id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS users_fts_delete AFTER DELETE ON users BEGIN
+	DELETE FROM users_fts WHERE id = old.id;
+END;`).Error
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			switch tx.Dialector.Name() {
+			case "postgres":
+				return tx.Exec("DROP INDEX IF EXISTS idx_users_fulltext").Error
+			case "mysql":
+				return tx.Exec("DROP INDEX idx_users_fulltext ON users").Error
+			case "sqlite":
+				return tx.Exec(`
+DROP TRIGGER IF EXISTS users_fts_insert;
+DROP TRIGGER IF EXISTS users_fts_update;
+DROP TRIGGER IF EXISTS users_fts_delete;
+DROP TABLE IF EXISTS users_fts;`).Error
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,103 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var (
+	migrationName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	fileVersion   = regexp.MustCompile(`^(\d+)_`)
+)
+
+var goTemplate = template.Must(template.New("migration").Parse(`package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	register(Migration{
+		Version: {{.Version}},
+		Name:    "{{.Name}}",
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
+`))
+
+// Create writes the files of a new migration into dir, the migrations
+// package directory, numbered after the newest migration found there or
+// built in. It writes a Go file when goFile is set and an up/down SQL pair
+// otherwise, and returns the paths written.
+func Create(dir, name string, goFile bool) ([]string, error) {
+	if !migrationName.MatchString(name) {
+		return nil, fmt.Errorf("migration name %q must be lower_snake_case", name)
+	}
+
+	version, err := nextVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	id := Migration{Version: version, Name: name}.ID()
+
+	if goFile {
+		var content strings.Builder
+		if err := goTemplate.Execute(&content, Migration{Version: version, Name: name}); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, id+".go")
+		return []string{path}, writeNew(path, content.String())
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, "sql", id+"."+direction+".sql")
+		content := fmt.Sprintf("-- %s %s\n", direction, name)
+		if err := writeNew(path, content); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func nextVersion(dir string) (int, error) {
+	latest := 0
+	for _, m := range Registered() {
+		latest = max(latest, m.Version)
+	}
+
+	for _, pattern := range []string{"*.go", "sql/*.sql"} {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return 0, err
+		}
+		for _, file := range files {
+			if match := fileVersion.FindStringSubmatch(filepath.Base(file)); match != nil {
+				version, _ := strconv.Atoi(match[1])
+				latest = max(latest, version)
+			}
+		}
+	}
+	return latest + 1, nil
+}
+
+func writeNew(path, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,355 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// lockKey and mysqlLockName identify the Postgres advisory lock and the
+// MySQL named lock held while migrating, so instances started together with
+// -migrate run one after another.
+const (
+	lockKey       int64 = 7246031934
+	mysqlLockName       = "golang-backend:migrate"
+)
+
+// schemaMigration is a row of schema_migrations, one per applied migration.
+type schemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status is a migration and whether it has been applied. Missing marks a
+// version recorded in schema_migrations that this binary does not contain.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	Missing   bool
+}
+
+// Migrator applies and reverts migrations. With DryRun set, pending
+// migrations run inside a transaction that is rolled back and the SQL that
+// changes the schema or data is written to Out instead of being kept. MySQL
+// refuses dry runs.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	DryRun     bool
+	Out        io.Writer
+}
+
+// NewMigrator manages the migrations built into this binary.
+func NewMigrator(db *gorm.DB) *Migrator {
+	return New(db, Registered())
+}
+
+// New manages the given migrations, which must be ordered by version.
+func New(db *gorm.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations, Out: os.Stdout}
+}
+
+// Up applies pending migrations in version order, at most limit of them
+// when limit is positive. It returns the migrations applied.
+func (m *Migrator) Up(limit int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+
+		latest := 0
+		for version := range applied {
+			latest = max(latest, version)
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if limit > 0 && len(done) == limit {
+				break
+			}
+			if migration.Version < latest {
+				log.Printf("Warning: applying %s after the newer migration %04d", migration.ID(), latest)
+			}
+
+			if err := m.run(db, migration, "up", migration.Up, func(tx *gorm.DB) error {
+				return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			}); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, newest first. It returns
+// the migrations reverted.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for _, version := range versions[:min(steps, len(versions))] {
+			migration, ok := m.find(version)
+			if !ok {
+				return fmt.Errorf("migration %04d_%s is applied but not in this build", version, applied[version].Name)
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %s cannot be reverted", migration.ID())
+			}
+
+			if err := m.run(db, migration, "down", migration.Down, func(tx *gorm.DB) error {
+				return tx.Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+			}); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Redo reverts the last applied migration and applies it again, to check
+// that its down migration works.
+func (m *Migrator) Redo() error {
+	reverted, err := m.Down(1)
+	if err != nil || len(reverted) == 0 || m.DryRun {
+		return err
+	}
+	_, err = m.Up(1)
+	return err
+}
+
+// Status lists every migration known to this binary or recorded in the
+// database, ordered by version.
+func (m *Migrator) Status() ([]Status, error) {
+	applied := map[int]schemaMigration{}
+	if m.db.Migrator().HasTable(&schemaMigration{}) {
+		var err error
+		if applied, err = m.applied(m.db); err != nil {
+			return nil, err
+		}
+	}
+
+	var statuses []Status
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		statuses = append(statuses, Status{Version: row.Version, Name: row.Name, AppliedAt: &row.AppliedAt, Missing: true})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// run executes one step and its bookkeeping in a transaction. In dry-run
+// mode db is already a transaction that will be rolled back, so the step
+// runs directly and only its SQL is kept, as printed output.
+func (m *Migrator) run(db *gorm.DB, migration Migration, direction string, step, record func(tx *gorm.DB) error) error {
+	if m.DryRun {
+		fmt.Fprintf(m.Out, "-- %s %s\n", direction, migration.ID())
+		if err := step(db); err != nil {
+			return fmt.Errorf("migrate %s %s: %w", direction, migration.ID(), err)
+		}
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := step(tx); err != nil {
+			return err
+		}
+		return record(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate %s %s: %w", direction, migration.ID(), err)
+	}
+	log.Printf("✓ Migrated %s %s", direction, migration.ID())
+	return nil
+}
+
+func (m *Migrator) applied(db *gorm.DB) (map[int]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// locked runs fn while holding the migration lock, so a second instance
+// waits until the first one is done. On Postgres that is a session advisory
+// lock and on MySQL a GET_LOCK named lock, both held by one pinned
+// connection that fn's handle is bound to. SQLite takes no lock: its
+// database is a local file, migrated by one process at a time. In dry-run
+// mode fn runs in a transaction that is rolled back afterwards, so later
+// migrations see the changes of earlier ones; MySQL commits schema changes
+// even inside a transaction, so it refuses dry runs.
+func (m *Migrator) locked(fn func(db *gorm.DB) error) error {
+	dialect := m.db.Dialector.Name()
+	if m.DryRun && dialect == "mysql" {
+		return errors.New("dry run is not supported on MySQL, which commits schema changes even inside a transaction")
+	}
+
+	db := m.db
+	if dialect == "postgres" || dialect == "mysql" {
+		conn, release, err := m.lock(context.Background(), dialect)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		db = m.db.Session(&gorm.Session{NewDB: true})
+		db.Statement.ConnPool = conn
+	}
+
+	if m.DryRun {
+		db = db.Session(&gorm.Session{Logger: &sqlPrinter{out: m.Out}}).Begin()
+		defer db.Rollback()
+	}
+
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(db)
+}
+
+// lock pins a connection and takes the migration lock on it. Waiting for
+// the lock and building indexes may outlast DB_STATEMENT_TIMEOUT, so the
+// timeout is lifted for the connection; release restores it, unlocks and
+// returns the connection to the pool.
+func (m *Migrator) lock(ctx context.Context, dialect string) (*sql.Conn, func(), error) {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var steps []func() error
+	release := func() {
+		for i := len(steps) - 1; i >= 0; i-- {
+			if err := steps[i](); err != nil {
+				log.Printf("Warning: failed to release migration lock: %v", err)
+			}
+		}
+		conn.Close()
+	}
+	exec := func(query string, args ...interface{}) error {
+		_, err := conn.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	if dialect == "postgres" {
+		if err := exec("SET statement_timeout = 0"); err != nil {
+			release()
+			return nil, nil, err
+		}
+		steps = append(steps, func() error { return exec("RESET statement_timeout") })
+
+		if err := exec("SELECT pg_advisory_lock($1)", lockKey); err != nil {
+			release()
+			return nil, nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+		steps = append(steps, func() error { return exec("SELECT pg_advisory_unlock($1)", lockKey) })
+		return conn, release, nil
+	}
+
+	// The DSN sets max_execution_time per session, so put back that value
+	var timeout int64
+	if err := conn.QueryRowContext(ctx, "SELECT @@SESSION.max_execution_time").Scan(&timeout); err != nil {
+		release()
+		return nil, nil, err
+	}
+	if err := exec("SET SESSION max_execution_time = 0"); err != nil {
+		release()
+		return nil, nil, err
+	}
+	steps = append(steps, func() error { return exec(fmt.Sprintf("SET SESSION max_execution_time = %d", timeout)) })
+
+	// GET_LOCK answers 1 once held and NULL on an error such as a deadlock
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, -1)", mysqlLockName).Scan(&acquired); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	if acquired.Int64 != 1 {
+		release()
+		return nil, nil, errors.New("acquire migration lock: GET_LOCK failed")
+	}
+	steps = append(steps, func() error { return exec("DO RELEASE_LOCK(?)", mysqlLockName) })
+	return conn, release, nil
+}
+
+// sqlPrinter is the gorm logger of dry runs. It prints statements that
+// change the schema or data and skips the queries gorm makes to inspect it.
+type sqlPrinter struct {
+	out io.Writer
+}
+
+func (p *sqlPrinter) LogMode(logger.LogLevel) logger.Interface      { return p }
+func (p *sqlPrinter) Info(context.Context, string, ...interface{})  {}
+func (p *sqlPrinter) Warn(context.Context, string, ...interface{})  {}
+func (p *sqlPrinter) Error(context.Context, string, ...interface{}) {}
+func (p *sqlPrinter) Trace(_ context.Context, _ time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	if isQuery(sql) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	fmt.Fprintf(p.out, "%s;\n", strings.TrimRight(strings.TrimSpace(sql), ";"))
+}
+
+func isQuery(sql string) bool {
+	sql = strings.ToUpper(strings.TrimSpace(sql))
+	return strings.HasPrefix(sql, "SELECT") || strings.HasPrefix(sql, "PRAGMA") || strings.HasPrefix(sql, "SHOW")
+}
@@ -1,19 +1,94 @@
 package migrations
 
 import (
+	"errors"
+	"fmt"
 	"log"
-
-	"golang-backend/entity"
+	"regexp"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
-func migrateRBAC(db *gorm.DB) {
-	err := db.AutoMigrate(
-		&entity.Role{},
-		&entity.Permission{},
-	)
+// structuredPermissionPattern is the "resource:action" format as it stood for
+// the initial schema, kept here so later changes to entity validation do not
+// alter what that migration does.
+var structuredPermissionPattern = regexp.MustCompile(`^[a-z0-9_\-.*?]+:[a-z0-9_\-.*?]+$`)
+
+// legacyPermissionActions maps the verbs of the old "verb_resource" permission
+// names to actions of the structured "resource:action" format.
+var legacyPermissionActions = map[string]string{
+	"view":   "read",
+	"read":   "read",
+	"create": "create",
+	"edit":   "update",
+	"update": "update",
+	"delete": "delete",
+	"manage": "*",
+}
+
+// migratePermissionNames renames legacy permissions such as "manage_users" to
+// "users:*". When the structured name already exists the two are merged. It
+// works on the frozen permission type of the initial schema.
+func migratePermissionNames(tx *gorm.DB) error {
+	var permissions []permission
+	if err := tx.Find(&permissions).Error; err != nil {
+		return fmt.Errorf("load permissions: %w", err)
+	}
+
+	for _, permission := range permissions {
+		if structuredPermissionPattern.MatchString(permission.Name) {
+			continue
+		}
+
+		name, ok := structuredPermissionName(permission.Name)
+		if !ok {
+			log.Printf("Warning: permission %q is not in resource:action format and was left unchanged", permission.Name)
+			continue
+		}
+
+		if err := renamePermission(tx, permission, name); err != nil {
+			return fmt.Errorf("migrate permission %s: %w", permission.Name, err)
+		}
+		log.Printf("✓ Permission %s renamed to %s", permission.Name, name)
+	}
+	return nil
+}
+
+func renamePermission(tx *gorm.DB, legacy permission, name string) error {
+	var existing permission
+	err := tx.Where("name = ?", name).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Model(&legacy).Update("name", name).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	// Move role grants onto the existing permission before dropping the legacy one
+	err = tx.Exec(`INSERT INTO role_permissions (role_id, permission_id)
+		SELECT role_id, ? FROM role_permissions
+		WHERE permission_id = ? AND role_id NOT IN (SELECT role_id FROM role_permissions WHERE permission_id = ?)`,
+		existing.ID, legacy.ID, existing.ID).Error
 	if err != nil {
-		log.Fatalf("Failed to migrate RBAC: %v", err)
+		return err
+	}
+	if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", legacy.ID).Error; err != nil {
+		return err
 	}
+	return tx.Unscoped().Delete(&legacy).Error
+}
+
+func structuredPermissionName(legacy string) (string, bool) {
+	verb, resource, ok := strings.Cut(legacy, "_")
+	if !ok || resource == "" {
+		return "", false
+	}
+
+	action, ok := legacyPermissionActions[verb]
+	if !ok {
+		return "", false
+	}
+
+	return resource + ":" + action, true
 }
@@ -0,0 +1,130 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one numbered schema change. Go migrations register themselves
+// from files named 0001_name.go; SQL migrations are the embedded pairs
+// sql/0003_name.up.sql and sql/0003_name.down.sql. SQL that only one
+// database understands goes in files named for its dialect, such as
+// sql/0003_name.postgres.up.sql, used instead of the plain file there.
+type Migration struct {
+	Version int
+	Name    string
+	// Up and Down run inside a transaction together with the
+	// schema_migrations bookkeeping. Down may be nil when the change cannot
+	// be reverted.
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+}
+
+// ID is the file name prefix, e.g. 0002_users_fulltext_index.
+func (m Migration) ID() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+var (
+	registered []Migration
+	sqlName    = regexp.MustCompile(`^(\d+)_(\w+)(?:\.(postgres|mysql|sqlite))?\.(up|down)\.sql$`)
+)
+
+// register adds a Go migration; it is called from the init func of each
+// numbered migration file.
+func register(m Migration) {
+	registered = append(registered, m)
+}
+
+// Registered returns the migrations built into this binary, Go and SQL,
+// ordered by version. A version used twice panics, as would a malformed SQL
+// file name, since both are caught by the first test run.
+func Registered() []Migration {
+	all := append([]Migration(nil), registered...)
+	all = append(all, embeddedSQL()...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+
+	for i := 1; i < len(all); i++ {
+		if all[i].Version == all[i-1].Version {
+			panic(fmt.Sprintf("migrations %s and %s share version %d", all[i-1].ID(), all[i].ID(), all[i].Version))
+		}
+	}
+	return all
+}
+
+// embeddedSQL turns the sql/ files into migrations that Exec their contents.
+func embeddedSQL() []Migration {
+	entries, err := sqlFiles.ReadDir("sql")
+	if err != nil {
+		panic(err)
+	}
+
+	type sqlMigration struct {
+		Migration
+		// files maps direction, then dialect ("" for any) to a file name
+		files map[string]map[string]string
+	}
+	byVersion := map[int]*sqlMigration{}
+	for _, entry := range entries {
+		match := sqlName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			panic(fmt.Sprintf("migration file %s must be named 0001_name.up.sql, 0001_name.down.sql or 0001_name.<postgres|mysql|sqlite>.up.sql", entry.Name()))
+		}
+		version, _ := strconv.Atoi(match[1])
+		name, dialect, direction := match[2], match[3], match[4]
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &sqlMigration{Migration: Migration{Version: version, Name: name}, files: map[string]map[string]string{}}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			panic(fmt.Sprintf("migration files %04d_%s and %s share version %d", version, m.Name, entry.Name(), version))
+		}
+		if m.files[direction] == nil {
+			m.files[direction] = map[string]string{}
+		}
+		m.files[direction][dialect] = path.Join("sql", entry.Name())
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.files["up"] == nil {
+			panic(fmt.Sprintf("migration %s has no .up.sql file", m.ID()))
+		}
+		m.Up = execFile(m.ID(), m.files["up"])
+		if m.files["down"] != nil {
+			m.Down = execFile(m.ID(), m.files["down"])
+		}
+		migrations = append(migrations, m.Migration)
+	}
+	return migrations
+}
+
+// execFile runs the file written for the dialect of tx, or else the plain one.
+func execFile(id string, files map[string]string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		name, ok := files[tx.Dialector.Name()]
+		if !ok {
+			name, ok = files[""]
+		}
+		if !ok {
+			return fmt.Errorf("migration %s has no SQL for %s", id, tx.Dialector.Name())
+		}
+
+		statements, err := sqlFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		return tx.Exec(string(statements)).Error
+	}
+}
@@ -0,0 +1,5 @@
+-- The hash chain shows tampering; the triggers stop casual edits outright
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs
+	FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs
+	FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang-backend/entity"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditChainLock is the Postgres advisory lock key serializing appends, so
+// two writers never chain onto the same previous record.
+const auditChainLock = 0x61756469
+
+// AuditLogRepository only appends and reads; audit records are never changed.
+type AuditLogRepository interface {
+	// Append assigns the next sequence number, links the record to the last
+	// hash of the chain and stores it.
+	Append(ctx context.Context, log *entity.AuditLog) error
+	Paginate(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	// FindAfter returns up to limit records following the given sequence, in order.
+	FindAfter(ctx context.Context, sequence int64, limit int) ([]entity.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Append(ctx context.Context, log *entity.AuditLog) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		latest := tx
+		switch tx.Dialector.Name() {
+		case "postgres":
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLock).Error; err != nil {
+				return err
+			}
+		case "mysql":
+			// The next-key lock on the newest record also blocks inserts after it
+			latest = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		// SQLite has one writer at a time; a racing append fails on the
+		// sequence primary key instead of forking the chain
+
+		var last entity.AuditLog
+		err := latest.Order("sequence desc").First(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		log.Sequence = last.Sequence + 1
+		log.PrevHash = last.Hash
+		// Postgres keeps microseconds; hash exactly what will be read back
+		log.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		log.Hash = log.ComputeHash()
+
+		return tx.Create(log).Error
+	})
+}
+
+func (r *auditLogRepository) Paginate(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	var logs []entity.AuditLog
+	var total int64
+	query := forReading(ctx, r.db).Model(&entity.AuditLog{})
+
+	for _, column := range []string{"actor_id", "action", "target_type", "target_id", "request_id"} {
+		if value, ok := filters[column].(string); ok && value != "" {
+			query.Where(column+" = ?", value)
+		}
+	}
+	if from, ok := filters["from"].(time.Time); ok && !from.IsZero() {
+		query.Where("created_at >= ?", from)
+	}
+	if to, ok := filters["to"].(time.Time); ok && !to.IsZero() {
+		query.Where("created_at < ?", to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("sequence desc").Limit(perPage).Offset(offset).Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.PaginationResult{
+		Items:      logs,
+		Pagination: utils.CalculatePagination(total, page, perPage),
+	}, nil
+}
+
+func (r *auditLogRepository) FindAfter(ctx context.Context, sequence int64, limit int) ([]entity.AuditLog, error) {
+	var logs []entity.AuditLog
+	err := conn(ctx, r.db).Where("sequence > ?", sequence).Order("sequence asc").Limit(limit).Find(&logs).Error
+	return logs, err
+}
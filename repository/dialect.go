@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Text search is written differently on each supported database. The
+// helpers below add the condition in the form the dialect of query expects.
+
+// containsFold adds a condition matching rows where any of the columns
+// contains term, ignoring case. Postgres needs ILIKE; LIKE already ignores
+// case under MySQL's default collations and for ASCII on SQLite.
+func containsFold(query *gorm.DB, term string, columns ...string) {
+	operator := "LIKE"
+	if query.Dialector.Name() == "postgres" {
+		operator = "ILIKE"
+	}
+
+	pattern := "%" + term + "%"
+	conditions := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		conditions[i] = column + " " + operator + " ?"
+		args[i] = pattern
+	}
+	query.Where(strings.Join(conditions, " OR "), args...)
+}
+
+// fullTextSearch adds a condition matching rows of table whose columns hold
+// every word as a word prefix, through the full-text index of the table
+// (see migration 0002). It reports false, adding nothing, when the database
+// has no such index and the caller should fall back to containsFold.
+func fullTextSearch(query *gorm.DB, table string, columns, words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+
+	switch query.Dialector.Name() {
+	case "postgres":
+		terms := make([]string, len(words))
+		for i, word := range words {
+			terms[i] = word + ":*"
+		}
+		// The expression must match the GIN index for it to be used
+		document := strings.Join(columns, " || ' ' || ")
+		query.Where("to_tsvector('indonesian', "+document+") @@ to_tsquery('indonesian', ?)", strings.Join(terms, " & "))
+	case "mysql":
+		terms := make([]string, len(words))
+		for i, word := range words {
+			terms[i] = "+" + word + "*"
+		}
+		query.Where("MATCH("+strings.Join(columns, ", ")+") AGAINST (? IN BOOLEAN MODE)", strings.Join(terms, " "))
+	case "sqlite":
+		// FTS5 is only compiled in with -tags sqlite_fts5; without it the
+		// migration skips the table
+		fts := table + "_fts"
+		if !query.Session(&gorm.Session{NewDB: true}).Migrator().HasTable(fts) {
+			return false
+		}
+		terms := make([]string, len(words))
+		for i, word := range words {
+			terms[i] = `"` + word + `"*`
+		}
+		query.Where(table+".id IN (SELECT id FROM "+fts+" WHERE "+fts+" MATCH ?)", strings.Join(terms, " "))
+	default:
+		return false
+	}
+	return true
+}
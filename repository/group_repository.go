@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"golang-backend/entity"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+type GroupRepository interface {
+	PaginateGroups(ctx context.Context, organizationID string, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	FindGroupByID(ctx context.Context, organizationID, id string) (*entity.Group, error)
+	FindGroupGraph(ctx context.Context, organizationIDs []string) ([]*entity.Group, error)
+	CreateGroup(ctx context.Context, group *entity.Group) error
+	UpdateGroup(ctx context.Context, group *entity.Group) error
+	DeleteGroup(ctx context.Context, group *entity.Group) error
+	AttachGroupRole(ctx context.Context, group *entity.Group, role *entity.Role) error
+	DetachGroupRole(ctx context.Context, group *entity.Group, role *entity.Role) error
+
+	PaginateGroupMembers(ctx context.Context, groupID string, page, perPage int) (*utils.PaginationResult, error)
+	AddGroupMember(ctx context.Context, member *entity.GroupMember) error
+	RemoveGroupMember(ctx context.Context, groupID, userID string) (bool, error)
+}
+
+type groupRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (r *groupRepository) PaginateGroups(ctx context.Context, organizationID string, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	var groups []entity.Group
+	var total int64
+	query := forReading(ctx, r.db).Model(&entity.Group{}).Where("organization_id = ?", organizationID)
+
+	if search, ok := filters["search"].(string); ok && search != "" {
+		containsFold(query, search, "name")
+	}
+	if parentID, ok := filters["parent_id"].(string); ok && parentID != "" {
+		query.Where("parent_id = ?", parentID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Preload("Roles").Order("name asc").Limit(perPage).Offset(offset).Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.PaginationResult{
+		Items:      groups,
+		Pagination: utils.CalculatePagination(total, page, perPage),
+	}, nil
+}
+
+func (r *groupRepository) FindGroupByID(ctx context.Context, organizationID, id string) (*entity.Group, error) {
+	var group entity.Group
+	err := conn(ctx, r.db).Preload("Roles").
+		Where("organization_id = ? AND id = ?", organizationID, id).
+		First(&group).Error
+	return &group, err
+}
+
+// FindGroupGraph loads every group of the organizations with its roles. Parent
+// links are left to the caller, see findGroupGraph.
+func (r *groupRepository) FindGroupGraph(ctx context.Context, organizationIDs []string) ([]*entity.Group, error) {
+	return findGroupGraph(conn(ctx, r.db), organizationIDs)
+}
+
+func (r *groupRepository) CreateGroup(ctx context.Context, group *entity.Group) error {
+	return conn(ctx, r.db).Omit("Parent", "Organization", "Roles").Create(group).Error
+}
+
+func (r *groupRepository) UpdateGroup(ctx context.Context, group *entity.Group) error {
+	return conn(ctx, r.db).Omit("Parent", "Organization", "Roles").Save(group).Error
+}
+
+// DeleteGroup moves the group's subgroups up to its parent, drops its members
+// and role grants, then deletes it for good, so the organization can reuse
+// its name under the unique index.
+func (r *groupRepository) DeleteGroup(ctx context.Context, group *entity.Group) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entity.Group{}).Where("parent_id = ?", group.ID).Update("parent_id", group.ParentID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&entity.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(group).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(group).Error
+	})
+}
+
+func (r *groupRepository) AttachGroupRole(ctx context.Context, group *entity.Group, role *entity.Role) error {
+	return conn(ctx, r.db).Model(group).Omit("Roles.*").Association("Roles").Append(role)
+}
+
+func (r *groupRepository) DetachGroupRole(ctx context.Context, group *entity.Group, role *entity.Role) error {
+	return conn(ctx, r.db).Model(group).Association("Roles").Delete(role)
+}
+
+// Members
+
+func (r *groupRepository) PaginateGroupMembers(ctx context.Context, groupID string, page, perPage int) (*utils.PaginationResult, error) {
+	var members []entity.GroupMember
+	var total int64
+	query := forReading(ctx, r.db).Model(&entity.GroupMember{}).Where("group_id = ?", groupID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Preload("User").Order("created_at asc").Limit(perPage).Offset(offset).Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.PaginationResult{
+		Items:      members,
+		Pagination: utils.CalculatePagination(total, page, perPage),
+	}, nil
+}
+
+func (r *groupRepository) AddGroupMember(ctx context.Context, member *entity.GroupMember) error {
+	return conn(ctx, r.db).Omit("Group", "User").Create(member).Error
+}
+
+// RemoveGroupMember reports whether the user was a member of the group.
+func (r *groupRepository) RemoveGroupMember(ctx context.Context, groupID, userID string) (bool, error) {
+	result := conn(ctx, r.db).Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&entity.GroupMember{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// findGroupGraph loads the groups of the organizations with their roles. Only
+// ParentID is set; callers link Parent from the returned slice.
+func findGroupGraph(db *gorm.DB, organizationIDs []string) ([]*entity.Group, error) {
+	groups := []*entity.Group{}
+	if len(organizationIDs) == 0 {
+		return groups, nil
+	}
+	err := db.Preload("Roles").Where("organization_id IN ?", organizationIDs).Find(&groups).Error
+	return groups, err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"golang-backend/entity"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository interface {
+	FindOrganizationByID(ctx context.Context, id string) (*entity.Organization, error)
+	FindOrganizationBySlug(ctx context.Context, slug string) (*entity.Organization, error)
+	CreateOrganization(ctx context.Context, organization *entity.Organization) error
+	FindMembershipsByUser(ctx context.Context, userID string) ([]*entity.Membership, error)
+	FindMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error)
+	CreateMembership(ctx context.Context, membership *entity.Membership) error
+	UpdateMembership(ctx context.Context, membership *entity.Membership) error
+	PaginateMembers(ctx context.Context, organizationID string, page, perPage int) (*utils.PaginationResult, error)
+	SetActiveOrganization(ctx context.Context, userID string, organizationID *string) error
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) FindOrganizationByID(ctx context.Context, id string) (*entity.Organization, error) {
+	var organization entity.Organization
+	err := conn(ctx, r.db).Where("id = ?", id).First(&organization).Error
+	return &organization, err
+}
+
+func (r *organizationRepository) FindOrganizationBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	var organization entity.Organization
+	err := conn(ctx, r.db).Where("slug = ?", slug).First(&organization).Error
+	return &organization, err
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, organization *entity.Organization) error {
+	return conn(ctx, r.db).Create(organization).Error
+}
+
+func (r *organizationRepository) FindMembershipsByUser(ctx context.Context, userID string) ([]*entity.Membership, error) {
+	memberships := []*entity.Membership{}
+	err := conn(ctx, r.db).Preload("Organization").Preload("Roles").
+		Where("user_id = ?", userID).
+		Order("created_at asc").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+func (r *organizationRepository) FindMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error) {
+	var membership entity.Membership
+	err := conn(ctx, r.db).Preload("Organization").Preload("Roles").
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(&membership).Error
+	return &membership, err
+}
+
+// CreateMembership stores the membership and links its roles without
+// upserting the role rows themselves.
+func (r *organizationRepository) CreateMembership(ctx context.Context, membership *entity.Membership) error {
+	return conn(ctx, r.db).Omit("Organization", "Roles.*").Create(membership).Error
+}
+
+func (r *organizationRepository) UpdateMembership(ctx context.Context, membership *entity.Membership) error {
+	return conn(ctx, r.db).Omit("Organization", "Roles").Save(membership).Error
+}
+
+func (r *organizationRepository) PaginateMembers(ctx context.Context, organizationID string, page, perPage int) (*utils.PaginationResult, error) {
+	var memberships []entity.Membership
+	var total int64
+	query := forReading(ctx, r.db).Model(&entity.Membership{}).Where("organization_id = ?", organizationID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Preload("User").Preload("Roles").Order("created_at asc").Limit(perPage).Offset(offset).Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.PaginationResult{
+		Items:      memberships,
+		Pagination: utils.CalculatePagination(total, page, perPage),
+	}, nil
+}
+
+func (r *organizationRepository) SetActiveOrganization(ctx context.Context, userID string, organizationID *string) error {
+	return conn(ctx, r.db).Model(&entity.User{}).Where("id = ?", userID).Update("active_organization_id", organizationID).Error
+}
+
+// TenantScope limits a users query to members of the organization. An empty
+// organizationID leaves the query unscoped.
+func TenantScope(organizationID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationID == "" {
+			return db
+		}
+		members := db.Session(&gorm.Session{NewDB: true}).Model(&entity.Membership{}).
+			Select("user_id").
+			Where("organization_id = ? AND status = ?", organizationID, entity.MembershipActive)
+		return db.Where("users.id IN (?)", members)
+	}
+}
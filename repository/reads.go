@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"golang-backend/config"
+
+	"gorm.io/gorm"
+)
+
+// forReading starts a list or search query that may be served by a read
+// replica (DB_REPLICAS). Users who just changed data still read from the
+// primary, so they see their change.
+func forReading(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return config.ReadFromReplica(conn(ctx, db))
+}
@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"golang-backend/entity"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	PaginateRoles(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	FindRoleByID(ctx context.Context, id string) (*entity.Role, error)
+	FindRoleByName(ctx context.Context, name string) (*entity.Role, error)
+	LockRole(ctx context.Context, role *entity.Role) error
+	CreateRole(ctx context.Context, role *entity.Role) error
+	UpdateRole(ctx context.Context, role *entity.Role) error
+	DeleteRole(ctx context.Context, role *entity.Role) error
+	AttachPermission(ctx context.Context, role *entity.Role, permission *entity.Permission) error
+	DetachPermission(ctx context.Context, role *entity.Role, permission *entity.Permission) error
+	ReplacePermissions(ctx context.Context, role *entity.Role, permissions []*entity.Permission) error
+	FindRoleGraph(ctx context.Context) ([]*entity.Role, error)
+	AttachParent(ctx context.Context, role, parent *entity.Role) error
+	DetachParent(ctx context.Context, role, parent *entity.Role) error
+
+	PaginatePermissions(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	FindPermissionByID(ctx context.Context, id string) (*entity.Permission, error)
+	FindPermissionByName(ctx context.Context, name string) (*entity.Permission, error)
+	FindPermissionsByNames(ctx context.Context, names []string) ([]*entity.Permission, error)
+	FindAllPermissions(ctx context.Context) ([]*entity.Permission, error)
+	CreatePermission(ctx context.Context, permission *entity.Permission) error
+	UpdatePermission(ctx context.Context, permission *entity.Permission) error
+	DeletePermission(ctx context.Context, permission *entity.Permission) error
+
+	FindUserWithRoles(ctx context.Context, userID string) (*entity.User, error)
+	FindPrincipal(ctx context.Context, userID string, at time.Time) (*entity.User, error)
+	FindUserGroupIDs(ctx context.Context, userID string) ([]string, error)
+	FindGroupGraph(ctx context.Context, organizationIDs []string) ([]*entity.Group, error)
+	AttachRole(ctx context.Context, assignment *entity.UserRole) error
+	DetachRole(ctx context.Context, user *entity.User, role *entity.Role) error
+	CountRoleHolders(ctx context.Context, roleID string, at time.Time) (int64, error)
+	CountPermanentRoleHolders(ctx context.Context, roleID, exceptUserID string, at time.Time) (int64, error)
+	DeleteExpiredRoleAssignments(ctx context.Context, at time.Time) (int64, error)
+	FindExpiringRoleAssignments(ctx context.Context, from, until time.Time) ([]ExpiringRoleAssignment, error)
+	MarkExpiryNotified(ctx context.Context, userID, roleID string, at time.Time) error
+	SaveUserPermission(ctx context.Context, userPermission *entity.UserPermission) error
+	DeleteUserPermission(ctx context.Context, userID, permissionID string) (bool, error)
+}
+
+// ExpiringRoleAssignment is a role assignment about to expire whose holder has
+// not been notified yet.
+type ExpiringRoleAssignment struct {
+	UserID    string
+	Email     string
+	Locale    string
+	RoleID    string
+	RoleName  string
+	ExpiresAt time.Time
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// Roles
+
+func (r *roleRepository) PaginateRoles(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	var roles []entity.Role
+	var total int64
+	query := forReading(ctx, r.db).Model(&entity.Role{})
+
+	if search, ok := filters["search"].(string); ok && search != "" {
+		containsFold(query, search, "name")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Preload("Permissions").Order("name asc").Limit(perPage).Offset(offset).Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.PaginationResult{
+		Items:      roles,
+		Pagination: utils.CalculatePagination(total, page, perPage),
+	}, nil
+}
+
+func (r *roleRepository) FindRoleByID(ctx context.Context, id string) (*entity.Role, error) {
+	var role entity.Role
+	err := conn(ctx, r.db).Preload("Permissions").Preload("Parents").Where("id = ?", id).First(&role).Error
+	return &role, err
+}
+
+func (r *roleRepository) FindRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	var role entity.Role
+	err := conn(ctx, r.db).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	return &role, err
+}
+
+// LockRole takes a row lock on the role until the surrounding transaction ends,
+// serializing concurrent changes to its holders.
+func (r *roleRepository) LockRole(ctx context.Context, role *entity.Role) error {
+	return conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", role.ID).
+		First(&entity.Role{}).Error
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, role *entity.Role) error {
+	return conn(ctx, r.db).Create(role).Error
+}
+
+func (r *roleRepository) UpdateRole(ctx context.Context, role *entity.Role) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(role).Error
+}
+
+// DeleteRole takes the role away from its users, memberships and groups, drops
+// its permissions and hierarchy links, then deletes it.
+func (r *roleRepository) DeleteRole(ctx context.Context, role *entity.Role) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"user_roles", "membership_roles", "group_roles"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE role_id = ?", role.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM role_parents WHERE role_id = ? OR parent_id = ?", role.ID, role.ID).Error; err != nil {
+			return err
+		}
+		// Hard delete so the unique name can be reused
+		return tx.Unscoped().Delete(role).Error
+	})
+}
+
+func (r *roleRepository) AttachPermission(ctx context.Context, role *entity.Role, permission *entity.Permission) error {
+	return conn(ctx, r.db).Model(role).Association("Permissions").Append(permission)
+}
+
+func (r *roleRepository) DetachPermission(ctx context.Context, role *entity.Role, permission *entity.Permission) error {
+	return conn(ctx, r.db).Model(role).Association("Permissions").Delete(permission)
+}
+
+func (r *roleRepository) ReplacePermissions(ctx context.Context, role *entity.Role, permissions []*entity.Permission) error {
+	return conn(ctx, r.db).Model(role).Association("Permissions").Replace(permissions)
+}
+
+// FindRoleGraph loads every role with its permissions and the IDs of its direct parents.
+// The parents are shallow copies; callers link them to build the full hierarchy.
+func (r *roleRepository) FindRoleGraph(ctx context.Context) ([]*entity.Role, error) {
+	var roles []*entity.Role
+	err := conn(ctx, r.db).Preload("Permissions").Preload("Parents").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) AttachParent(ctx context.Context, role, parent *entity.Role) error {
+	// Skip upserting the parent: it may carry a linked hierarchy we must not re-save
+	return conn(ctx, r.db).Model(role).Omit("Parents.*").Association("Parents").Append(parent)
+}
+
+func (r *roleRepository) DetachParent(ctx context.Context, role, parent *entity.Role) error {
+	return conn(ctx, r.db).Model(role).Association("Parents").Delete(parent)
+}
+
+// Permissions
+
+func (r *roleRepository) PaginatePermissions(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	var permissions []entity.Permission
+	var total int64
+	query := forReading(ctx, r.db).Model(&entity.Permission{})
+
+	if search, ok := filters["search"].(string); ok && search != "" {
+		containsFold(query, search, "name")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * perPage
+	if err := query.Order("name asc").Limit(perPage).Offset(offset).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	return &utils.PaginationResult{
+		Items:      permissions,
+		Pagination: utils.CalculatePagination(total, page, perPage),
+	}, nil
+}
+
+func (r *roleRepository) FindPermissionByID(ctx context.Context, id string) (*entity.Permission, error) {
+	var permission entity.Permission
+	err := conn(ctx, r.db).Where("id = ?", id).First(&permission).Error
+	return &permission, err
+}
+
+func (r *roleRepository) FindPermissionByName(ctx context.Context, name string) (*entity.Permission, error) {
+	var permission entity.Permission
+	err := conn(ctx, r.db).Where("name = ?", name).First(&permission).Error
+	return &permission, err
+}
+
+func (r *roleRepository) FindPermissionsByNames(ctx context.Context, names []string) ([]*entity.Permission, error) {
+	permissions := []*entity.Permission{}
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	err := conn(ctx, r.db).Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepository) FindAllPermissions(ctx context.Context) ([]*entity.Permission, error) {
+	var permissions []*entity.Permission
+	err := conn(ctx, r.db).Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepository) CreatePermission(ctx context.Context, permission *entity.Permission) error {
+	return conn(ctx, r.db).Create(permission).Error
+}
+
+func (r *roleRepository) UpdatePermission(ctx context.Context, permission *entity.Permission) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(permission).Error
+}
+
+func (r *roleRepository) DeletePermission(ctx context.Context, permission *entity.Permission) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", permission.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_permissions WHERE permission_id = ?", permission.ID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(permission).Error
+	})
+}
+
+// User assignments
+
+// FindUserWithRoles loads the user with every role assignment, including
+// scheduled and expired ones that have not been swept yet.
+func (r *roleRepository) FindUserWithRoles(ctx context.Context, userID string) (*entity.User, error) {
+	var user entity.User
+	err := conn(ctx, r.db).Preload("Roles.Permissions").Preload("UserPermissions.Permission").Where("id = ?", userID).First(&user).Error
+	return &user, err
+}
+
+// FindPrincipal loads the user with only the role assignments in effect at the
+// given time, plus their active organization memberships and per-org roles.
+func (r *roleRepository) FindPrincipal(ctx context.Context, userID string, at time.Time) (*entity.User, error) {
+	var user entity.User
+	err := conn(ctx, r.db).
+		Preload("Roles", "roles.id IN (?)", r.activeAssignments(at).Select("role_id").Where("user_id = ?", userID)).
+		Preload("Roles.Permissions").
+		Preload("UserPermissions.Permission").
+		Preload("Memberships", "status = ?", entity.MembershipActive).
+		Preload("Memberships.Organization").
+		Preload("Memberships.Roles.Permissions").
+		Where("id = ?", userID).
+		First(&user).Error
+	return &user, err
+}
+
+// FindUserGroupIDs returns the groups the user was added to directly.
+func (r *roleRepository) FindUserGroupIDs(ctx context.Context, userID string) ([]string, error) {
+	groupIDs := []string{}
+	err := conn(ctx, r.db).Model(&entity.GroupMember{}).Where("user_id = ?", userID).Pluck("group_id", &groupIDs).Error
+	return groupIDs, err
+}
+
+func (r *roleRepository) FindGroupGraph(ctx context.Context, organizationIDs []string) ([]*entity.Group, error) {
+	return findGroupGraph(conn(ctx, r.db), organizationIDs)
+}
+
+// AttachRole creates the assignment, or replaces the grant details and validity
+// window when the user already has the role.
+func (r *roleRepository) AttachRole(ctx context.Context, assignment *entity.UserRole) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"granted_by", "granted_at", "starts_at", "expires_at", "expiry_notified_at",
+		}),
+	}).Create(assignment).Error
+}
+
+func (r *roleRepository) DetachRole(ctx context.Context, user *entity.User, role *entity.Role) error {
+	return conn(ctx, r.db).Where("user_id = ? AND role_id = ?", user.ID, role.ID).Delete(&entity.UserRole{}).Error
+}
+
+// CountRoleHolders counts the active (not soft-deleted) users holding the role at the given time.
+func (r *roleRepository) CountRoleHolders(ctx context.Context, roleID string, at time.Time) (int64, error) {
+	var holders int64
+	err := conn(ctx, r.db).Model(&entity.User{}).
+		Where("id IN (?)", r.activeAssignments(at).Select("user_id").Where("role_id = ?", roleID)).
+		Count(&holders).Error
+	return holders, err
+}
+
+// CountPermanentRoleHolders counts the active users other than exceptUserID
+// holding the role at the given time with no expiry.
+func (r *roleRepository) CountPermanentRoleHolders(ctx context.Context, roleID, exceptUserID string, at time.Time) (int64, error) {
+	var holders int64
+	err := conn(ctx, r.db).Model(&entity.User{}).
+		Where("id IN (?)", r.activeAssignments(at).Select("user_id").Where("role_id = ? AND expires_at IS NULL", roleID)).
+		Where("id <> ?", exceptUserID).
+		Count(&holders).Error
+	return holders, err
+}
+
+func (r *roleRepository) DeleteExpiredRoleAssignments(ctx context.Context, at time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at IS NOT NULL AND expires_at <= ?", at).Delete(&entity.UserRole{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *roleRepository) FindExpiringRoleAssignments(ctx context.Context, from, until time.Time) ([]ExpiringRoleAssignment, error) {
+	var assignments []ExpiringRoleAssignment
+	err := conn(ctx, r.db).Table("user_roles").
+		Select("user_roles.user_id, users.email, users.locale, user_roles.role_id, roles.name AS role_name, user_roles.expires_at").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.expires_at > ? AND user_roles.expires_at <= ?", from, until).
+		Where("user_roles.expiry_notified_at IS NULL").
+		Scan(&assignments).Error
+	return assignments, err
+}
+
+func (r *roleRepository) MarkExpiryNotified(ctx context.Context, userID, roleID string, at time.Time) error {
+	return conn(ctx, r.db).Model(&entity.UserRole{}).
+		Where("user_id = ? AND role_id = ?", userID, roleID).
+		Update("expiry_notified_at", at).Error
+}
+
+// activeAssignments scopes user_roles to assignments in effect at the given time.
+func (r *roleRepository) activeAssignments(at time.Time) *gorm.DB {
+	return r.db.Model(&entity.UserRole{}).
+		Where("starts_at IS NULL OR starts_at <= ?", at).
+		Where("expires_at IS NULL OR expires_at > ?", at)
+}
+
+// SaveUserPermission creates the user's entry for the permission, or switches
+// the effect of an existing one.
+func (r *roleRepository) SaveUserPermission(ctx context.Context, userPermission *entity.UserPermission) error {
+	return conn(ctx, r.db).Omit("Permission").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "permission_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"effect"}),
+	}).Create(userPermission).Error
+}
+
+// DeleteUserPermission removes the user's entry for the permission and reports whether one existed.
+func (r *roleRepository) DeleteUserPermission(ctx context.Context, userID, permissionID string) (bool, error) {
+	result := conn(ctx, r.db).Where("user_id = ? AND permission_id = ?", userID, permissionID).Delete(&entity.UserPermission{})
+	return result.RowsAffected > 0, result.Error
+}
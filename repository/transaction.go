@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// transactionAttempts is how often a transaction runs before a serialization
+// failure or deadlock is returned to the caller.
+const transactionAttempts = 3
+
+type txKey struct{}
+
+// TransactionManager runs several repository calls as one unit of work.
+type TransactionManager interface {
+	// Transaction runs fn in a transaction carried by the ctx it is given;
+	// every repository call made with that ctx joins it. The transaction
+	// commits when fn returns nil and rolls back otherwise. Called inside
+	// another transaction it becomes a savepoint, so only its own changes
+	// roll back. A serialization failure or deadlock reruns the whole
+	// transaction, so fn must leave side effects such as emails to after
+	// it returns.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error
+}
+
+type transactionManager struct {
+	db *gorm.DB
+}
+
+func NewTransactionManager(db *gorm.DB) TransactionManager {
+	return &transactionManager{db: db}
+}
+
+func (m *transactionManager) Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error {
+	run := func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	}
+
+	// gorm turns a transaction within a transaction into a savepoint
+	if outer, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return outer.WithContext(ctx).Transaction(run, opts...)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := m.db.WithContext(ctx).Transaction(run, opts...)
+		if err == nil || attempt == transactionAttempts || !retryable(err) {
+			return err
+		}
+
+		slog.WarnContext(ctx, "Transaction conflicted, retrying", "attempt", attempt, "error", err)
+		backoff := time.Duration(attempt)*20*time.Millisecond + rand.N(20*time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// conn returns the transaction of ctx, or db when there is none, bound to
+// ctx. Repository methods start every query with it.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// retryable reports whether err aborted a transaction that may succeed when
+// run again: a Postgres serialization failure or deadlock, or a MySQL
+// deadlock.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1213
+}
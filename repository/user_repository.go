@@ -1,20 +1,26 @@
 package repository
 
 import (
+	"context"
 	"golang-backend/entity"
 	"golang-backend/utils"
 	"regexp"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
-	FindByEmail(email string) (*entity.User, error)
-	FindByID(id string) (*entity.User, error)
-	Create(user *entity.User) error
-	Update(user *entity.User) error
-	Paginate(filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	// EmailExists also counts soft-deleted users, as the unique index does.
+	EmailExists(ctx context.Context, email string) (bool, error)
+	FindByID(ctx context.Context, id string) (*entity.User, error)
+	// FindMemberByID is FindByID limited to members of the organization.
+	FindMemberByID(ctx context.Context, id, organizationID string) (*entity.User, error)
+	Create(ctx context.Context, user *entity.User) error
+	Update(ctx context.Context, user *entity.User) error
+	Paginate(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
 }
 
 type userRepository struct {
@@ -25,30 +31,49 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) FindByID(id string) (*entity.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.Where("id = ?", id).First(&user).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&user).Error
 	return &user, err
 }
 
-func (r *userRepository) FindByEmail(email string) (*entity.User, error) {
+func (r *userRepository) FindMemberByID(ctx context.Context, id, organizationID string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := TenantScope(organizationID)(conn(ctx, r.db)).Where("id = ?", id).First(&user).Error
 	return &user, err
 }
 
-func (r *userRepository) Create(user *entity.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	var user entity.User
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
+	return &user, err
+}
+
+func (r *userRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Unscoped().Model(&entity.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
 }
 
-func (r *userRepository) Update(user *entity.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
+	return conn(ctx, r.db).Create(user).Error
 }
 
-func (r *userRepository) Paginate(filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+// Update saves the user's own columns; roles and permissions change through
+// RoleRepository, so loaded associations are never written back.
+func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(user).Error
+}
+
+func (r *userRepository) Paginate(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
 	var users []entity.User
 	var total int64
-	query := r.db.Model(&entity.User{})
+	query := forReading(ctx, r.db).Model(&entity.User{})
+
+	// Tenant isolation: only members of the active organization
+	if organizationID, ok := filters["organization_id"].(string); ok {
+		query = TenantScope(organizationID)(query)
+	}
 
 	// Smart Search
 	if search, ok := filters["search"].(string); ok && search != "" {
@@ -108,11 +133,10 @@ func (r *userRepository) Paginate(filters map[string]interface{}, page, perPage
 // Smart Search Logic
 
 func (r *userRepository) applySmartSearch(query *gorm.DB, searchTerm string) {
-	if r.shouldUseFullText(searchTerm) {
-		r.applyFullTextSearch(query, searchTerm)
-	} else {
-		r.applyLikeSearch(query, searchTerm)
+	if r.shouldUseFullText(searchTerm) && r.applyFullTextSearch(query, searchTerm) {
+		return
 	}
+	r.applyLikeSearch(query, searchTerm)
 }
 
 func (r *userRepository) shouldUseFullText(searchTerm string) bool {
@@ -120,28 +144,26 @@ func (r *userRepository) shouldUseFullText(searchTerm string) bool {
 	return len(searchTerm) >= 3 && !regexp.MustCompile(`^\d+$`).MatchString(searchTerm)
 }
 
-func (r *userRepository) applyFullTextSearch(query *gorm.DB, searchTerm string) {
-	tsQuery := r.preparePrefixSearchTerm(searchTerm)
-	// Postgres specific
-	query.Where("to_tsvector('indonesian', name || ' ' || email) @@ to_tsquery('indonesian', ?)", tsQuery)
-	// Optional: Select relevance and sort by it? For now just filter.
+// applyFullTextSearch reports false when the database offers no full-text
+// search over users.
+func (r *userRepository) applyFullTextSearch(query *gorm.DB, searchTerm string) bool {
+	return fullTextSearch(query, "users", []string{"name", "email"}, r.searchWords(searchTerm))
 }
 
-func (r *userRepository) preparePrefixSearchTerm(searchTerm string) string {
-	words := strings.Fields(searchTerm)
-	var tsQueryParts []string
+// searchWords splits the term into words stripped of the characters that
+// full-text query syntaxes treat as operators.
+func (r *userRepository) searchWords(searchTerm string) []string {
+	var words []string
 	re := regexp.MustCompile(`[^a-zA-Z0-9\s]`)
-	for _, word := range words {
-		clean := re.ReplaceAllString(word, "")
-		if clean != "" {
-			tsQueryParts = append(tsQueryParts, clean+":*")
+	for _, word := range strings.Fields(searchTerm) {
+		if clean := re.ReplaceAllString(word, ""); clean != "" {
+			words = append(words, clean)
 		}
 	}
-	return strings.Join(tsQueryParts, " & ")
+	return words
 }
 
 func (r *userRepository) applyLikeSearch(query *gorm.DB, searchTerm string) {
-	// Fallback for short terms or non-Postgres (though query above assumes Postgres for fulltext)
-	search := "%" + searchTerm + "%"
-	query.Where("name ILIKE ? OR email ILIKE ?", search, search)
+	// Fallback for short terms and databases without a full-text index
+	containsFold(query, searchTerm, "name", "email")
 }
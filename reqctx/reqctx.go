@@ -0,0 +1,63 @@
+// Package reqctx carries request-scoped values in a context.Context: the
+// request ID and client, and the authenticated principal. Middleware stores
+// them on the request, and services, repositories and logs read them
+// without depending on gin.
+package reqctx
+
+import (
+	"context"
+
+	"golang-backend/entity"
+)
+
+type contextKey int
+
+const (
+	requestKey contextKey = iota
+	principalKey
+)
+
+// Request describes the HTTP request behind a context.
+type Request struct {
+	ID        string
+	IP        string
+	UserAgent string
+}
+
+// WithRequest returns a copy of ctx carrying the request.
+func WithRequest(ctx context.Context, request Request) context.Context {
+	return context.WithValue(ctx, requestKey, request)
+}
+
+// RequestFrom returns the request of ctx; it is empty outside a request,
+// e.g. in background jobs.
+func RequestFrom(ctx context.Context) Request {
+	request, _ := ctx.Value(requestKey).(Request)
+	return request
+}
+
+// RequestID returns the ID of the request of ctx, or "".
+func RequestID(ctx context.Context) string {
+	return RequestFrom(ctx).ID
+}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated user,
+// loaded with the roles and permissions authorization checks against.
+func WithPrincipal(ctx context.Context, user *entity.User) context.Context {
+	return context.WithValue(ctx, principalKey, user)
+}
+
+// Principal returns the authenticated user of ctx, or nil when the request
+// is anonymous.
+func Principal(ctx context.Context) *entity.User {
+	user, _ := ctx.Value(principalKey).(*entity.User)
+	return user
+}
+
+// PrincipalID returns the ID of the authenticated user of ctx, or "".
+func PrincipalID(ctx context.Context) string {
+	if user := Principal(ctx); user != nil {
+		return user.ID
+	}
+	return ""
+}
@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"net/http"
+
 	"golang-backend/controller"
 	"golang-backend/middleware"
 	"golang-backend/utils"
@@ -13,7 +15,22 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(app *gin.Engine, userCtrl *controller.UserController, roleCtrl *controller.RoleController) {
+// SetupRoutes registers every route. Routes that need a permission go through
+// the registry so the permissions table can be synced with them.
+func SetupRoutes(
+	app *gin.Engine,
+	registry *middleware.RouteRegistry,
+	userCtrl *controller.UserController,
+	roleCtrl *controller.RoleController,
+	routeCtrl *controller.RouteController,
+	orgCtrl *controller.OrganizationController,
+	groupCtrl *controller.GroupController,
+	auditCtrl *controller.AuditController,
+	configCtrl *controller.ConfigController,
+	principals middleware.PrincipalLoader,
+	tokens *utils.TokenManager,
+	tenantDomain string,
+) {
 	app.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	api := app.Group("/api")
@@ -29,16 +46,69 @@ func SetupRoutes(app *gin.Engine, userCtrl *controller.UserController, roleCtrl
 	api.POST("/resend-reset-code", userCtrl.ResendResetPasswordCode)
 
 	protected := api.Group("/")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(principals, tokens), middleware.TenantMiddleware(tenantDomain))
 	protected.GET("/me", userCtrl.Me)
-	protected.GET("/users", userCtrl.GetUsers)
+	// Per-org roles count as on the organization's routes, so the answers
+	// match what /organizations/current and /users decide for the same tenant
+	protected.GET("/me/permissions", middleware.OrganizationScope(), roleCtrl.GetMyPermissions)
+	protected.POST("/me/can", middleware.OrganizationScope(), roleCtrl.CheckMyPermissions)
 	protected.POST("/2fa/setup", userCtrl.Setup2FA)
 	protected.POST("/2fa/verify", userCtrl.Verify2FA)
 
+	protected.GET("/organizations", orgCtrl.ListOrganizations)
+	protected.POST("/organizations", orgCtrl.CreateOrganization)
+	protected.POST("/organizations/switch", orgCtrl.SwitchOrganization)
+	protected.POST("/organizations/accept", orgCtrl.AcceptInvitation)
+
+	// Per-org roles count only on routes acting inside the request's organization
+	current := protected.Group("/organizations/current", middleware.OrganizationScope())
+	registry.Handle(current, http.MethodGet, "/members", "members:read", orgCtrl.ListMembers)
+	registry.Handle(current, http.MethodPost, "/members", "members:create", orgCtrl.InviteMember)
+
+	// The handlers run resource policies, so they check the permission themselves.
+	// With a tenant they see only its members, so per-org roles count too
+	users := protected.Group("/users", middleware.OrganizationScope())
+	registry.HandlePolicy(users, http.MethodGet, "", "users:read", userCtrl.GetUsers)
+	registry.HandlePolicy(users, http.MethodGet, "/:id", "users:read", userCtrl.GetUser)
+	registry.HandlePolicy(users, http.MethodPut, "/:id", "users:update", userCtrl.UpdateUser)
+
 	admin := protected.Group("/admin")
-	admin.Use(middleware.RoleAuthMiddleware("admin"))
-	admin.POST("/roles", roleCtrl.CreateRole)
-	admin.POST("/permissions", roleCtrl.CreatePermission)
-	admin.POST("/assign-role", roleCtrl.AssignRoleToUser)
-	admin.POST("/assign-permission", roleCtrl.AssignPermissionToRole)
+	registry.Handle(admin, http.MethodGet, "/roles", "roles:read", roleCtrl.ListRoles)
+	registry.Handle(admin, http.MethodPost, "/roles", "roles:create", roleCtrl.CreateRole)
+	registry.Handle(admin, http.MethodGet, "/roles/:id", "roles:read", roleCtrl.GetRole)
+	registry.Handle(admin, http.MethodPut, "/roles/:id", "roles:update", roleCtrl.UpdateRole)
+	registry.Handle(admin, http.MethodDelete, "/roles/:id", "roles:delete", roleCtrl.DeleteRole)
+	registry.Handle(admin, http.MethodPut, "/roles/:id/permissions", "roles:update", roleCtrl.SyncRolePermissions)
+	registry.Handle(admin, http.MethodGet, "/roles/:id/effective-permissions", "roles:read", roleCtrl.GetEffectivePermissions)
+	registry.Handle(admin, http.MethodPost, "/roles/:id/parents", "roles:update", roleCtrl.AddParentRole)
+	registry.Handle(admin, http.MethodDelete, "/roles/:id/parents/:parentId", "roles:update", roleCtrl.RemoveParentRole)
+	registry.Handle(admin, http.MethodGet, "/permissions", "permissions:read", roleCtrl.ListPermissions)
+	registry.Handle(admin, http.MethodPost, "/permissions", "permissions:create", roleCtrl.CreatePermission)
+	registry.Handle(admin, http.MethodPut, "/permissions/:id", "permissions:update", roleCtrl.UpdatePermission)
+	registry.Handle(admin, http.MethodDelete, "/permissions/:id", "permissions:delete", roleCtrl.DeletePermission)
+	registry.Handle(admin, http.MethodPost, "/assign-role", "roles:assign", roleCtrl.AssignRoleToUser)
+	registry.Handle(admin, http.MethodPost, "/revoke-role", "roles:assign", roleCtrl.RevokeRoleFromUser)
+	registry.Handle(admin, http.MethodPost, "/assign-permission", "roles:update", roleCtrl.AssignPermissionToRole)
+	registry.Handle(admin, http.MethodPost, "/revoke-permission", "roles:update", roleCtrl.RevokePermissionFromRole)
+	registry.Handle(admin, http.MethodPost, "/grant-user-permission", "permissions:assign", roleCtrl.GrantUserPermission)
+	registry.Handle(admin, http.MethodPost, "/revoke-user-permission", "permissions:assign", roleCtrl.RevokeUserPermission)
+	registry.Handle(admin, http.MethodGet, "/users/:id/permissions", "permissions:read", roleCtrl.GetUserPermissions)
+	registry.Handle(admin, http.MethodPost, "/users/:id/can", "permissions:read", roleCtrl.CheckUserPermissions)
+	registry.Handle(admin, http.MethodGet, "/routes", "routes:read", routeCtrl.ListRoutes)
+	registry.Handle(admin, http.MethodGet, "/audit-logs", "audit-logs:read", auditCtrl.ListAuditLogs)
+	registry.Handle(admin, http.MethodGet, "/audit-logs/verify", "audit-logs:read", auditCtrl.VerifyAuditLogs)
+	registry.Handle(admin, http.MethodGet, "/config", "config:read", configCtrl.GetConfig)
+
+	// Groups belong to the organization resolved for the request
+	groups := admin.Group("/groups", middleware.OrganizationScope())
+	registry.Handle(groups, http.MethodGet, "", "groups:read", groupCtrl.ListGroups)
+	registry.Handle(groups, http.MethodPost, "", "groups:create", groupCtrl.CreateGroup)
+	registry.Handle(groups, http.MethodGet, "/:id", "groups:read", groupCtrl.GetGroup)
+	registry.Handle(groups, http.MethodPut, "/:id", "groups:update", groupCtrl.UpdateGroup)
+	registry.Handle(groups, http.MethodDelete, "/:id", "groups:delete", groupCtrl.DeleteGroup)
+	registry.Handle(groups, http.MethodGet, "/:id/members", "groups:read", groupCtrl.ListGroupMembers)
+	registry.Handle(groups, http.MethodPost, "/:id/members", "groups:update", groupCtrl.AddGroupMember)
+	registry.Handle(groups, http.MethodDelete, "/:id/members/:userId", "groups:update", groupCtrl.RemoveGroupMember)
+	registry.Handle(groups, http.MethodPost, "/:id/roles", "groups:assign", groupCtrl.GrantGroupRole)
+	registry.Handle(groups, http.MethodDelete, "/:id/roles/:roleId", "groups:assign", groupCtrl.RevokeGroupRole)
 }
@@ -0,0 +1,154 @@
+// Package secrets encrypts the secrets file read by the config loader. The
+// file holds a YAML map of environment variable names to values, e.g.
+// "DB_PASSWORD: s3cret", sealed with AES-256-GCM under a master key.
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// KeySize is the length of a master key in bytes.
+const KeySize = 32
+
+// header starts every secrets file and is authenticated with the contents,
+// so a file written by another format version is rejected.
+const header = "golang-backend-secrets:v1\n"
+
+var (
+	ErrInvalidKey = errors.New("master key must be 32 bytes, base64 encoded")
+	ErrDecrypt    = errors.New("cannot decrypt secrets file: wrong master key or the file was modified")
+)
+
+// GenerateKey returns a new random master key, base64 encoded.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a master key produced by GenerateKey.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext into the secrets file format.
+func Seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(header))
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	out.WriteString(base64.StdEncoding.EncodeToString(sealed))
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// Open decrypts data written by Seal.
+func Open(key, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, ok := bytes.CutPrefix(data, []byte(header))
+	if !ok {
+		return nil, errors.New("not a secrets file")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(body)))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(header))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// Parse reads the decrypted YAML map of variable names to values.
+func Parse(plaintext []byte) (map[string]string, error) {
+	values := map[string]string{}
+	if err := yaml.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("secrets must be a map of NAME: value: %w", err)
+	}
+	return values, nil
+}
+
+// ReadFile decrypts and parses the secrets file at path.
+func ReadFile(path string, key []byte) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := Open(key, data)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(plaintext)
+}
+
+// WriteFile seals plaintext and replaces the file at path atomically, so an
+// interrupted write never leaves a truncated secrets file.
+func WriteFile(path string, key, plaintext []byte) error {
+	data, err := Seal(key, plaintext)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+
+	"golang-backend/dto"
+	"golang-backend/entity"
+	"golang-backend/repository"
+	"golang-backend/reqctx"
+	"golang-backend/utils"
+)
+
+// auditVerifyBatch is how many records VerifyChain loads at a time.
+const auditVerifyBatch = 500
+
+// AuditEntry is an action to record. Before and After are snapshots of the
+// target (structs or maps); only the fields that differ are stored, so
+// snapshots must never carry secrets. ActorID defaults to the principal of
+// the request and is set by flows that authenticate the user themselves,
+// such as login.
+type AuditEntry struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     interface{}
+	After      interface{}
+}
+
+type AuditService interface {
+	// Record appends an entry to the audit log, taking the IP, user agent
+	// and request ID from ctx. A failure is logged rather than returned so it
+	// never undoes the action being audited.
+	Record(ctx context.Context, entry AuditEntry)
+	ListAuditLogs(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	// VerifyChain re-hashes the whole log and reports the first broken record.
+	VerifyChain(ctx context.Context) (*dto.AuditChainReport, error)
+}
+
+type auditService struct {
+	repo repository.AuditLogRepository
+}
+
+func NewAuditService(repo repository.AuditLogRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) Record(ctx context.Context, entry AuditEntry) {
+	request := reqctx.RequestFrom(ctx)
+	changes, err := auditChanges(entry.Before, entry.After)
+	if err != nil {
+		slog.Error("Failed to diff audit entry", "error", err, "action", entry.Action)
+		changes = "{}"
+	}
+
+	log := &entity.AuditLog{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   truncate(entry.TargetID, 64),
+		Changes:    changes,
+		IP:         truncate(request.IP, 45),
+		UserAgent:  truncate(request.UserAgent, 255),
+		RequestID:  truncate(request.ID, 64),
+	}
+	actorID := entry.ActorID
+	if actorID == "" {
+		actorID = reqctx.PrincipalID(ctx)
+	}
+	if actorID != "" {
+		log.ActorID = &actorID
+	}
+
+	// The action already happened, so the record is written even if the
+	// client has gone away or the request deadline has passed
+	if err := s.repo.Append(context.WithoutCancel(ctx), log); err != nil {
+		slog.Error("Failed to write audit log", "error", err, "action", entry.Action, "request_id", request.ID)
+	}
+}
+
+func (s *auditService) ListAuditLogs(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	result, err := s.repo.Paginate(ctx, filters, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, _ := result.Items.([]entity.AuditLog)
+	responses := make([]dto.AuditLogResponse, 0, len(logs))
+	for i := range logs {
+		responses = append(responses, auditLogResponse(&logs[i]))
+	}
+	result.Items = responses
+	return result, nil
+}
+
+func (s *auditService) VerifyChain(ctx context.Context) (*dto.AuditChainReport, error) {
+	report := &dto.AuditChainReport{Valid: true}
+	var prev entity.AuditLog
+
+	for {
+		logs, err := s.repo.FindAfter(ctx, prev.Sequence, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range logs {
+			log := &logs[i]
+			reason := ""
+			switch {
+			case log.Sequence != prev.Sequence+1:
+				reason = "Records before this one are missing"
+			case log.PrevHash != prev.Hash:
+				reason = "Link to the previous record does not match"
+			case log.Hash != log.ComputeHash():
+				reason = "Record content does not match its hash"
+			}
+			if reason != "" {
+				report.Valid = false
+				report.BrokenAt = &log.Sequence
+				report.Reason = reason
+				return report, nil
+			}
+
+			report.Checked++
+			prev = *log
+		}
+
+		if len(logs) < auditVerifyBatch {
+			return report, nil
+		}
+	}
+}
+
+// auditChanges returns the JSON diff between two snapshots as
+// {"field": {"before": x, "after": y}}, keeping only changed fields.
+func auditChanges(before, after interface{}) (string, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return "", err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return "", err
+	}
+
+	changes := map[string]map[string]interface{}{}
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			changes[field] = map[string]interface{}{"before": value, "after": afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = map[string]interface{}{"before": nil, "after": value}
+		}
+	}
+
+	encoded, err := json.Marshal(changes)
+	return string(encoded), err
+}
+
+// auditFields flattens a snapshot into its top-level JSON fields.
+func auditFields(snapshot interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if snapshot == nil {
+		return fields, nil
+	}
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func auditLogResponse(log *entity.AuditLog) dto.AuditLogResponse {
+	return dto.AuditLogResponse{
+		Sequence:   log.Sequence,
+		CreatedAt:  log.CreatedAt,
+		ActorID:    log.ActorID,
+		Action:     log.Action,
+		TargetType: log.TargetType,
+		TargetID:   log.TargetID,
+		Changes:    json.RawMessage(log.Changes),
+		IP:         log.IP,
+		UserAgent:  log.UserAgent,
+		RequestID:  log.RequestID,
+		PrevHash:   log.PrevHash,
+		Hash:       log.Hash,
+	}
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+
+	"golang-backend/apperror"
+
+	"gorm.io/gorm"
+)
+
+// Error kinds returned by the services. They are apperror codes, so callers
+// match them with errors.Is and the error handler maps them to a status.
+var (
+	ErrNotFound   = apperror.NotFound
+	ErrConflict   = apperror.Conflict
+	ErrValidation = apperror.Validation
+)
+
+// Error is the error type returned by the services; its message is safe to
+// show to clients.
+type Error = apperror.Error
+
+func notFound(message string) error {
+	return apperror.NotFound.New(message)
+}
+
+func conflict(message string) error {
+	return apperror.Conflict.New(message)
+}
+
+func invalid(message string) error {
+	return apperror.Validation.New(message)
+}
+
+func unauthorized(message string) error {
+	return apperror.Unauthorized.New(message)
+}
+
+// lookupError turns a missing record into a not found error and passes any
+// other database error through untouched.
+func lookupError(err error, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound.Wrap(err, message)
+	}
+	return err
+}
+
+// writeError turns a unique constraint violation into a conflict error.
+func writeError(err error, message string) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apperror.Conflict.Wrap(err, message)
+	}
+	return err
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"golang-backend/dto"
+	"golang-backend/entity"
+	"golang-backend/events"
+	"golang-backend/repository"
+	"golang-backend/utils"
+)
+
+// GroupService manages the groups of an organization. Every method takes the
+// organization of the request so one tenant cannot reach another's groups.
+type GroupService interface {
+	ListGroups(ctx context.Context, organizationID string, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	GetGroup(ctx context.Context, organizationID, id string) (*dto.GroupResponse, error)
+	CreateGroup(ctx context.Context, organizationID string, input dto.CreateGroupRequest) (*dto.GroupResponse, error)
+	UpdateGroup(ctx context.Context, organizationID, id string, input dto.UpdateGroupRequest) (*dto.GroupResponse, error)
+	DeleteGroup(ctx context.Context, organizationID, id string) error
+
+	ListGroupMembers(ctx context.Context, organizationID, groupID string, page, perPage int) (*utils.PaginationResult, error)
+	AddGroupMember(ctx context.Context, organizationID, groupID, userID, addedBy string) error
+	RemoveGroupMember(ctx context.Context, organizationID, groupID, userID string) error
+	GrantGroupRole(ctx context.Context, organizationID, groupID, roleName string) (*dto.GroupResponse, error)
+	RevokeGroupRole(ctx context.Context, organizationID, groupID, roleID string) error
+}
+
+type groupService struct {
+	repo          repository.GroupRepository
+	organizations repository.OrganizationRepository
+	roles         repository.RoleRepository
+	tx            repository.TransactionManager
+	bus           *events.Bus
+}
+
+func NewGroupService(
+	repo repository.GroupRepository,
+	organizations repository.OrganizationRepository,
+	roles repository.RoleRepository,
+	tx repository.TransactionManager,
+	bus *events.Bus,
+) GroupService {
+	return &groupService{repo: repo, organizations: organizations, roles: roles, tx: tx, bus: bus}
+}
+
+func (s *groupService) ListGroups(ctx context.Context, organizationID string, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	result, err := s.repo.PaginateGroups(ctx, organizationID, filters, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, _ := result.Items.([]entity.Group)
+	responses := make([]dto.GroupResponse, 0, len(groups))
+	for i := range groups {
+		responses = append(responses, groupResponse(&groups[i]))
+	}
+	result.Items = responses
+	return result, nil
+}
+
+func (s *groupService) GetGroup(ctx context.Context, organizationID, id string) (*dto.GroupResponse, error) {
+	graph, err := s.groupGraph(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	group, ok := graph[id]
+	if !ok {
+		return nil, notFound("Group not found")
+	}
+
+	response := groupResponse(group)
+	response.EffectiveRoles = roleNames(group.EffectiveRoles())
+	return &response, nil
+}
+
+func (s *groupService) CreateGroup(ctx context.Context, organizationID string, input dto.CreateGroupRequest) (*dto.GroupResponse, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, invalid("Group name is required")
+	}
+
+	group := &entity.Group{
+		OrganizationID: organizationID,
+		Name:           name,
+		Description:    input.Description,
+	}
+
+	if input.ParentID != nil && *input.ParentID != "" {
+		parent, err := s.repo.FindGroupByID(ctx, organizationID, *input.ParentID)
+		if err != nil {
+			return nil, lookupError(err, "Parent group not found")
+		}
+		group.ParentID = &parent.ID
+	}
+
+	if err := s.repo.CreateGroup(ctx, group); err != nil {
+		return nil, writeError(err, "Group name already exists")
+	}
+
+	response := groupResponse(group)
+	return &response, nil
+}
+
+func (s *groupService) UpdateGroup(ctx context.Context, organizationID, id string, input dto.UpdateGroupRequest) (*dto.GroupResponse, error) {
+	var group *entity.Group
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		graph, err := s.groupGraph(ctx, organizationID)
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		group, ok = graph[id]
+		if !ok {
+			return notFound("Group not found")
+		}
+
+		if input.Name != nil {
+			group.Name = strings.TrimSpace(*input.Name)
+			if group.Name == "" {
+				return invalid("Group name is required")
+			}
+		}
+		if input.Description != nil {
+			group.Description = *input.Description
+		}
+		if input.ParentID != nil {
+			if err := setGroupParent(group, *input.ParentID, graph); err != nil {
+				return err
+			}
+		}
+
+		return writeError(s.repo.UpdateGroup(ctx, group), "Group name already exists")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Moving a group changes what its members inherit
+	if input.ParentID != nil {
+		s.rbacChanged()
+	}
+
+	response := groupResponse(group)
+	return &response, nil
+}
+
+func (s *groupService) DeleteGroup(ctx context.Context, organizationID, id string) error {
+	group, err := s.repo.FindGroupByID(ctx, organizationID, id)
+	if err != nil {
+		return lookupError(err, "Group not found")
+	}
+
+	if err := s.repo.DeleteGroup(ctx, group); err != nil {
+		return err
+	}
+
+	s.rbacChanged()
+	return nil
+}
+
+// Members
+
+func (s *groupService) ListGroupMembers(ctx context.Context, organizationID, groupID string, page, perPage int) (*utils.PaginationResult, error) {
+	group, err := s.repo.FindGroupByID(ctx, organizationID, groupID)
+	if err != nil {
+		return nil, lookupError(err, "Group not found")
+	}
+
+	result, err := s.repo.PaginateGroupMembers(ctx, group.ID, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	members, _ := result.Items.([]entity.GroupMember)
+	responses := make([]dto.GroupMemberResponse, 0, len(members))
+	for i := range members {
+		responses = append(responses, groupMemberResponse(&members[i]))
+	}
+	result.Items = responses
+	return result, nil
+}
+
+func (s *groupService) AddGroupMember(ctx context.Context, organizationID, groupID, userID, addedBy string) error {
+	group, err := s.repo.FindGroupByID(ctx, organizationID, groupID)
+	if err != nil {
+		return lookupError(err, "Group not found")
+	}
+
+	membership, err := s.organizations.FindMembership(ctx, organizationID, userID)
+	if err != nil {
+		return lookupError(err, "User is not a member of this organization")
+	}
+	if !membership.IsActive() {
+		return invalid("User has not accepted the organization invitation yet")
+	}
+
+	member := &entity.GroupMember{GroupID: group.ID, UserID: userID}
+	if addedBy != "" {
+		member.AddedBy = &addedBy
+	}
+	if err := s.repo.AddGroupMember(ctx, member); err != nil {
+		return writeError(err, "User is already a member of this group")
+	}
+
+	s.userChanged(userID)
+	return nil
+}
+
+func (s *groupService) RemoveGroupMember(ctx context.Context, organizationID, groupID, userID string) error {
+	group, err := s.repo.FindGroupByID(ctx, organizationID, groupID)
+	if err != nil {
+		return lookupError(err, "Group not found")
+	}
+
+	removed, err := s.repo.RemoveGroupMember(ctx, group.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return notFound("User is not a member of this group")
+	}
+
+	s.userChanged(userID)
+	return nil
+}
+
+// Roles
+
+func (s *groupService) GrantGroupRole(ctx context.Context, organizationID, groupID, roleName string) (*dto.GroupResponse, error) {
+	group, err := s.repo.FindGroupByID(ctx, organizationID, groupID)
+	if err != nil {
+		return nil, lookupError(err, "Group not found")
+	}
+
+	role, err := s.roles.FindRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, lookupError(err, "Role not found")
+	}
+	if ok, err := tenantRole(ctx, s.roles, role); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, invalid("Only roles limited to organization permissions can be granted to a group")
+	}
+
+	for _, existing := range group.Roles {
+		if existing.ID == role.ID {
+			return nil, conflict("Group already has this role")
+		}
+	}
+
+	if err := s.repo.AttachGroupRole(ctx, group, role); err != nil {
+		return nil, err
+	}
+	group.Roles = append(group.Roles, role)
+
+	s.rbacChanged()
+	response := groupResponse(group)
+	return &response, nil
+}
+
+func (s *groupService) RevokeGroupRole(ctx context.Context, organizationID, groupID, roleID string) error {
+	group, err := s.repo.FindGroupByID(ctx, organizationID, groupID)
+	if err != nil {
+		return lookupError(err, "Group not found")
+	}
+
+	for _, role := range group.Roles {
+		if role.ID == roleID {
+			if err := s.repo.DetachGroupRole(ctx, group, role); err != nil {
+				return err
+			}
+			s.rbacChanged()
+			return nil
+		}
+	}
+	return notFound("Group does not have this role")
+}
+
+// Helpers
+
+// groupGraph loads the organization's groups keyed by ID with parents linked.
+func (s *groupService) groupGraph(ctx context.Context, organizationID string) (map[string]*entity.Group, error) {
+	groups, err := s.repo.FindGroupGraph(ctx, []string{organizationID})
+	if err != nil {
+		return nil, err
+	}
+	return linkGroups(groups), nil
+}
+
+// setGroupParent moves the group under parentID, or to the top level when it
+// is empty. A parent nested below the group would close a cycle.
+func setGroupParent(group *entity.Group, parentID string, graph map[string]*entity.Group) error {
+	if parentID == "" {
+		group.ParentID = nil
+		group.Parent = nil
+		return nil
+	}
+
+	parent, ok := graph[parentID]
+	if !ok {
+		return notFound("Parent group not found")
+	}
+	if parent.DescendsFrom(group.ID) {
+		return conflict("Group hierarchy cannot contain cycles")
+	}
+
+	group.ParentID = &parent.ID
+	group.Parent = parent
+	return nil
+}
+
+// Group changes reach every member and subgroup, so drop all cached principals
+func (s *groupService) rbacChanged() {
+	s.bus.Publish(events.Event{Name: events.RBACChanged})
+}
+
+func (s *groupService) userChanged(userID string) {
+	s.bus.Publish(events.Event{Name: events.UserChanged, UserID: userID})
+}
+
+func groupResponse(group *entity.Group) dto.GroupResponse {
+	return dto.GroupResponse{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		ParentID:    group.ParentID,
+		Roles:       roleNames(group.Roles),
+		CreatedAt:   group.CreatedAt,
+	}
+}
+
+func groupMemberResponse(member *entity.GroupMember) dto.GroupMemberResponse {
+	response := dto.GroupMemberResponse{UserID: member.UserID, AddedAt: member.CreatedAt}
+	if member.User != nil {
+		response.Name = member.User.Name
+		response.Email = member.User.Email
+	}
+	return response
+}
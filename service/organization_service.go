@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang-backend/dto"
+	"golang-backend/entity"
+	"golang-backend/events"
+	"golang-backend/repository"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, ownerID, name, slug string) (*dto.OrganizationResponse, error)
+	ListOrganizations(ctx context.Context, userID string) ([]dto.OrganizationResponse, error)
+	InviteMember(ctx context.Context, organizationID, inviterID, email, roleName string) (*dto.MemberResponse, error)
+	AcceptInvitation(ctx context.Context, organizationID, userID string) error
+	// SwitchOrganization makes the organization the user's default tenant and
+	// returns a token carrying it as the org_id claim.
+	SwitchOrganization(ctx context.Context, userID, organizationID string) (*dto.SwitchOrganizationResponse, error)
+	ListMembers(ctx context.Context, organizationID string, page, perPage int) (*utils.PaginationResult, error)
+}
+
+type organizationService struct {
+	repo   repository.OrganizationRepository
+	users  repository.UserRepository
+	roles  repository.RoleRepository
+	tx     repository.TransactionManager
+	bus    *events.Bus
+	tokens *utils.TokenManager
+	mailer *utils.Mailer
+}
+
+func NewOrganizationService(
+	repo repository.OrganizationRepository,
+	users repository.UserRepository,
+	roles repository.RoleRepository,
+	tx repository.TransactionManager,
+	bus *events.Bus,
+	tokens *utils.TokenManager,
+	mailer *utils.Mailer,
+) OrganizationService {
+	return &organizationService{repo: repo, users: users, roles: roles, tx: tx, bus: bus, tokens: tokens, mailer: mailer}
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, ownerID, name, slug string) (*dto.OrganizationResponse, error) {
+	name = strings.TrimSpace(name)
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if name == "" {
+		return nil, invalid("Organization name is required")
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, invalid("Slug may only contain lowercase letters, digits and dashes")
+	}
+
+	owner, err := s.roles.FindRoleByName(ctx, entity.RoleOrganizationOwner)
+	if err != nil {
+		return nil, lookupError(err, "Organization owner role not found, run the seeder")
+	}
+
+	organization := &entity.Organization{Name: name, Slug: slug}
+	now := time.Now()
+	membership := &entity.Membership{
+		UserID:   ownerID,
+		Status:   entity.MembershipActive,
+		JoinedAt: &now,
+		Roles:    []*entity.Role{owner},
+	}
+
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateOrganization(ctx, organization); err != nil {
+			return writeError(err, "Slug is already taken")
+		}
+		membership.OrganizationID = organization.ID
+		return s.repo.CreateMembership(ctx, membership)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.userChanged(ownerID)
+	membership.Organization = organization
+	response := organizationResponse(membership)
+	return &response, nil
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context, userID string) ([]dto.OrganizationResponse, error) {
+	memberships, err := s.repo.FindMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make([]dto.OrganizationResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		organizations = append(organizations, organizationResponse(membership))
+	}
+	return organizations, nil
+}
+
+func (s *organizationService) InviteMember(ctx context.Context, organizationID, inviterID, email, roleName string) (*dto.MemberResponse, error) {
+	if roleName == "" {
+		roleName = entity.RoleOrganizationMember
+	}
+	organization, err := s.repo.FindOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return nil, lookupError(err, "Organization not found")
+	}
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, lookupError(err, "No user is registered with this email")
+	}
+
+	role, err := s.roles.FindRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, lookupError(err, "Role not found")
+	}
+	if ok, err := tenantRole(ctx, s.roles, role); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, invalid("Only roles limited to organization permissions can be granted inside an organization")
+	}
+
+	if _, err := s.repo.FindMembership(ctx, organization.ID, user.ID); err == nil {
+		return nil, conflict("User is already a member or has a pending invitation")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	membership := &entity.Membership{
+		OrganizationID: organization.ID,
+		UserID:         user.ID,
+		Status:         entity.MembershipInvited,
+		InvitedBy:      &inviterID,
+		Roles:          []*entity.Role{role},
+	}
+	if err := s.repo.CreateMembership(ctx, membership); err != nil {
+		return nil, writeError(err, "User is already a member or has a pending invitation")
+	}
+
+	go func() {
+		if err := s.mailer.SendOrganizationInviteEmail(user.Email, organization.Name, user.Locale); err != nil {
+			slog.Error("Failed to send organization invite", "error", err, "organization_id", organization.ID)
+		}
+	}()
+
+	membership.User = user
+	response := memberResponse(membership)
+	return &response, nil
+}
+
+func (s *organizationService) AcceptInvitation(ctx context.Context, organizationID, userID string) error {
+	membership, err := s.repo.FindMembership(ctx, organizationID, userID)
+	if err != nil {
+		return lookupError(err, "Invitation not found")
+	}
+	if membership.IsActive() {
+		return conflict("Invitation already accepted")
+	}
+
+	now := time.Now()
+	membership.Status = entity.MembershipActive
+	membership.JoinedAt = &now
+	if err := s.repo.UpdateMembership(ctx, membership); err != nil {
+		return err
+	}
+
+	s.userChanged(userID)
+	return nil
+}
+
+func (s *organizationService) SwitchOrganization(ctx context.Context, userID, organizationID string) (*dto.SwitchOrganizationResponse, error) {
+	membership, err := s.repo.FindMembership(ctx, organizationID, userID)
+	if err != nil {
+		return nil, lookupError(err, "Not a member of this organization")
+	}
+	if !membership.IsActive() {
+		return nil, invalid("Accept the invitation before switching to this organization")
+	}
+
+	if err := s.repo.SetActiveOrganization(ctx, userID, &membership.OrganizationID); err != nil {
+		return nil, err
+	}
+	s.userChanged(userID)
+
+	token, err := s.tokens.GenerateOrganizationToken(userID, membership.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SwitchOrganizationResponse{OrganizationID: membership.OrganizationID, Token: token}, nil
+}
+
+func (s *organizationService) ListMembers(ctx context.Context, organizationID string, page, perPage int) (*utils.PaginationResult, error) {
+	result, err := s.repo.PaginateMembers(ctx, organizationID, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, _ := result.Items.([]entity.Membership)
+	members := make([]dto.MemberResponse, 0, len(memberships))
+	for i := range memberships {
+		members = append(members, memberResponse(&memberships[i]))
+	}
+	result.Items = members
+	return result, nil
+}
+
+func (s *organizationService) userChanged(userID string) {
+	s.bus.Publish(events.Event{Name: events.UserChanged, UserID: userID})
+}
+
+func organizationResponse(membership *entity.Membership) dto.OrganizationResponse {
+	response := dto.OrganizationResponse{
+		ID:     membership.OrganizationID,
+		Status: membership.Status,
+		Roles:  roleNames(membership.Roles),
+	}
+	if membership.Organization != nil {
+		response.Name = membership.Organization.Name
+		response.Slug = membership.Organization.Slug
+	}
+	return response
+}
+
+func memberResponse(membership *entity.Membership) dto.MemberResponse {
+	response := dto.MemberResponse{
+		UserID:   membership.UserID,
+		Status:   membership.Status,
+		Roles:    roleNames(membership.Roles),
+		JoinedAt: membership.JoinedAt,
+	}
+	if membership.User != nil {
+		response.Name = membership.User.Name
+		response.Email = membership.User.Email
+	}
+	return response
+}
+
+// tenantRole reports whether the role may be granted inside an organization.
+// Admin, roles inheriting it and roles holding any permission outside
+// entity.TenantResources, such as "*:*" or "roles:assign", would reach the
+// global /api/admin routes and may not.
+func tenantRole(ctx context.Context, roles repository.RoleRepository, role *entity.Role) (bool, error) {
+	graph, err := linkRoleGraph(ctx, roles)
+	if err != nil {
+		return false, err
+	}
+	if linked, ok := graph[role.ID]; ok {
+		role = linked
+	}
+
+	if role.Implies(entity.RoleAdmin) {
+		return false, nil
+	}
+	for _, permission := range role.EffectivePermissions() {
+		if !entity.IsTenantPermission(permission.Name) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func roleNames(roles []*entity.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"golang-backend/cache"
+	"golang-backend/entity"
+	"golang-backend/events"
+)
+
+const principalKeyPrefix = "principal:"
+
+// PrincipalCache wraps LoadPrincipal with a cache so authenticated requests
+// skip the role and permission queries. Entries are dropped on the events
+// published by RBACService and UserService and otherwise live for ttl, which
+// also bounds how late a time-bound role starts or stops applying.
+type PrincipalCache struct {
+	rbac  RBACService
+	store cache.Store
+	ttl   time.Duration
+}
+
+func NewPrincipalCache(rbac RBACService, store cache.Store, ttl time.Duration, bus *events.Bus) *PrincipalCache {
+	pc := &PrincipalCache{rbac: rbac, store: store, ttl: ttl}
+
+	bus.Subscribe(events.UserChanged, func(event events.Event) {
+		pc.invalidate(pc.store.Delete(principalKeyPrefix + event.UserID))
+	})
+	bus.Subscribe(events.RBACChanged, func(events.Event) {
+		pc.invalidate(pc.store.DeletePrefix(principalKeyPrefix))
+	})
+
+	return pc
+}
+
+// LoadPrincipal serves the principal from the cache, loading and storing it on
+// a miss. A failing store degrades to loading from the database.
+func (pc *PrincipalCache) LoadPrincipal(ctx context.Context, userID string) (*entity.User, error) {
+	key := principalKeyPrefix + userID
+
+	cached, err := pc.store.Get(key)
+	if err == nil {
+		var user entity.User
+		if err := json.Unmarshal(cached, &user); err == nil {
+			return &user, nil
+		}
+	} else if !errors.Is(err, cache.ErrMiss) {
+		slog.Warn("Principal cache read failed", "error", err)
+	}
+
+	user, err := pc.rbac.LoadPrincipal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stripSecrets(user)
+
+	if encoded, err := json.Marshal(user); err == nil {
+		if err := pc.store.Set(key, encoded, pc.ttl); err != nil {
+			slog.Warn("Principal cache write failed", "error", err)
+		}
+	}
+
+	return user, nil
+}
+
+func (pc *PrincipalCache) invalidate(err error) {
+	if err != nil {
+		slog.Error("Principal cache invalidation failed", "error", err)
+	}
+}
+
+// stripSecrets clears credentials the principal never needs, so they are not
+// copied into a shared cache.
+func stripSecrets(user *entity.User) {
+	user.Password = ""
+	user.TwoFASecret = ""
+	user.VerificationCode = ""
+	user.ResetToken = ""
+}
@@ -0,0 +1,876 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang-backend/apperror"
+	"golang-backend/entity"
+	"golang-backend/events"
+	"golang-backend/repository"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const maxRBACNameLength = 100
+
+type RBACService interface {
+	ListRoles(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	GetRole(ctx context.Context, id string) (*entity.Role, error)
+	CreateRole(ctx context.Context, name string) (*entity.Role, error)
+	UpdateRole(ctx context.Context, id, name string) (*entity.Role, error)
+	DeleteRole(ctx context.Context, id string) error
+	SyncRolePermissions(ctx context.Context, id string, permissionNames []string) (*entity.Role, error)
+	AddParentRole(ctx context.Context, id, parentName string) (*entity.Role, error)
+	RemoveParentRole(ctx context.Context, id, parentID string) error
+	GetEffectivePermissions(ctx context.Context, id string) ([]*entity.Permission, error)
+
+	ListPermissions(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	GetPermission(ctx context.Context, id string) (*entity.Permission, error)
+	CreatePermission(ctx context.Context, name string) (*entity.Permission, error)
+	UpdatePermission(ctx context.Context, id, name string) (*entity.Permission, error)
+	DeletePermission(ctx context.Context, id string) error
+	// SyncPermissions creates the route-required permissions that are missing
+	// and reports stored ones no route can use.
+	SyncPermissions(ctx context.Context, required []string) (*PermissionSyncReport, error)
+
+	AssignRoleToUser(ctx context.Context, userID, roleName string, grant RoleGrant) error
+	RevokeRoleFromUser(ctx context.Context, userID, roleName string) error
+	// AssignPermissionToRole and RevokePermissionFromRole return the changed role.
+	AssignPermissionToRole(ctx context.Context, roleName, permissionName string) (*entity.Role, error)
+	RevokePermissionFromRole(ctx context.Context, roleName, permissionName string) (*entity.Role, error)
+	GrantUserPermission(ctx context.Context, userID, permissionName, effect string) (*entity.UserPermission, error)
+	RevokeUserPermission(ctx context.Context, userID, permissionName string) error
+
+	// LoadPrincipal loads a user whose roles are linked into the full role
+	// hierarchy, ready for HasRole/HasPermission checks.
+	LoadPrincipal(ctx context.Context, userID string) (*entity.User, error)
+
+	// SweepExpiredRoles deletes role assignments whose window has closed.
+	SweepExpiredRoles(ctx context.Context) (int64, error)
+	// NotifyExpiringRoles emails holders of assignments expiring within the
+	// given duration, once per assignment.
+	NotifyExpiringRoles(ctx context.Context, within time.Duration) (int, error)
+}
+
+// PermissionSyncReport is the outcome of SyncPermissions. Orphaned permissions
+// are only reported, never deleted, since roles may still hold them.
+type PermissionSyncReport struct {
+	Created  []string
+	Orphaned []string
+}
+
+// RoleGrant describes who grants a role and when the assignment is in effect.
+// A nil StartsAt takes effect immediately; a nil ExpiresAt never expires.
+type RoleGrant struct {
+	GrantedBy string
+	StartsAt  *time.Time
+	ExpiresAt *time.Time
+}
+
+func (g RoleGrant) timeBound() bool {
+	return g.StartsAt != nil || g.ExpiresAt != nil
+}
+
+type rbacService struct {
+	repo   repository.RoleRepository
+	tx     repository.TransactionManager
+	bus    *events.Bus
+	mailer *utils.Mailer
+}
+
+// NewRBACService publishes events on bus after every change that can alter a
+// user's principal. bus may be nil; mailer sends role expiry notices.
+func NewRBACService(repo repository.RoleRepository, tx repository.TransactionManager, bus *events.Bus, mailer *utils.Mailer) RBACService {
+	return &rbacService{repo: repo, tx: tx, bus: bus, mailer: mailer}
+}
+
+// Roles
+
+func (s *rbacService) ListRoles(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	return s.repo.PaginateRoles(ctx, filters, page, perPage)
+}
+
+func (s *rbacService) GetRole(ctx context.Context, id string) (*entity.Role, error) {
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Role not found")
+	}
+	return role, nil
+}
+
+func (s *rbacService) CreateRole(ctx context.Context, name string) (*entity.Role, error) {
+	name, err := validateRBACName("Role", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureRoleNameFree(ctx, name); err != nil {
+		return nil, err
+	}
+
+	role := &entity.Role{Name: name}
+	if err := s.repo.CreateRole(ctx, role); err != nil {
+		return nil, writeError(err, "Role already exists")
+	}
+
+	return role, nil
+}
+
+func (s *rbacService) UpdateRole(ctx context.Context, id, name string) (*entity.Role, error) {
+	name, err := validateRBACName("Role", name)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.GetRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.Name == entity.RoleAdmin {
+		return nil, conflict("The admin role cannot be renamed")
+	}
+	if role.Name == name {
+		return role, nil
+	}
+
+	if err := s.ensureRoleNameFree(ctx, name); err != nil {
+		return nil, err
+	}
+
+	role.Name = name
+	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		return nil, writeError(err, "Role already exists")
+	}
+
+	s.rbacChanged()
+	return role, nil
+}
+
+func (s *rbacService) DeleteRole(ctx context.Context, id string) error {
+	role, err := s.GetRole(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Deleting the admin role would strip every admin at once, including the last one
+	if role.Name == entity.RoleAdmin {
+		return conflict("The admin role cannot be deleted")
+	}
+
+	if err := s.repo.DeleteRole(ctx, role); err != nil {
+		return err
+	}
+
+	s.rbacChanged()
+	return nil
+}
+
+func (s *rbacService) SyncRolePermissions(ctx context.Context, id string, permissionNames []string) (*entity.Role, error) {
+	names := uniqueNames(permissionNames)
+
+	var role *entity.Role
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		role, err = s.repo.FindRoleByID(ctx, id)
+		if err != nil {
+			return lookupError(err, "Role not found")
+		}
+
+		permissions, err := s.repo.FindPermissionsByNames(ctx, names)
+		if err != nil {
+			return err
+		}
+
+		if missing := missingPermissions(names, permissions); len(missing) > 0 {
+			return apperror.NotFound.New("Permission not found").WithDetails(missing)
+		}
+
+		if err := s.repo.ReplacePermissions(ctx, role, permissions); err != nil {
+			return err
+		}
+		role.Permissions = permissions
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.rbacChanged()
+	return role, nil
+}
+
+// Hierarchy
+
+func (s *rbacService) AddParentRole(ctx context.Context, id, parentName string) (*entity.Role, error) {
+	var role *entity.Role
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		graph, err := linkRoleGraph(ctx, s.repo)
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		role, ok = graph[id]
+		if !ok {
+			return notFound("Role not found")
+		}
+
+		found, err := s.repo.FindRoleByName(ctx, parentName)
+		if err != nil {
+			return lookupError(err, "Parent role not found")
+		}
+		parent := graph[found.ID]
+
+		if parent.ID == role.ID {
+			return invalid("A role cannot inherit from itself")
+		}
+		for _, existing := range role.Parents {
+			if existing.ID == parent.ID {
+				return conflict("Role already inherits from this role")
+			}
+		}
+		// The new edge closes a cycle if the parent already inherits from the role
+		if parent.Implies(role.Name) {
+			return conflict("Role hierarchy cannot contain cycles")
+		}
+
+		if err := s.repo.AttachParent(ctx, role, parent); err != nil {
+			return err
+		}
+		role.Parents = append(role.Parents, parent)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.rbacChanged()
+	return role, nil
+}
+
+func (s *rbacService) RemoveParentRole(ctx context.Context, id, parentID string) error {
+	role, err := s.GetRole(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, parent := range role.Parents {
+		if parent.ID == parentID {
+			if err := s.repo.DetachParent(ctx, role, parent); err != nil {
+				return err
+			}
+			s.rbacChanged()
+			return nil
+		}
+	}
+
+	return notFound("Role does not inherit from this role")
+}
+
+func (s *rbacService) GetEffectivePermissions(ctx context.Context, id string) ([]*entity.Permission, error) {
+	graph, err := linkRoleGraph(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	role, ok := graph[id]
+	if !ok {
+		return nil, notFound("Role not found")
+	}
+
+	permissions := role.EffectivePermissions()
+	if permissions == nil {
+		permissions = []*entity.Permission{}
+	}
+	return permissions, nil
+}
+
+// Permissions
+
+func (s *rbacService) ListPermissions(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	return s.repo.PaginatePermissions(ctx, filters, page, perPage)
+}
+
+func (s *rbacService) GetPermission(ctx context.Context, id string) (*entity.Permission, error) {
+	permission, err := s.repo.FindPermissionByID(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Permission not found")
+	}
+	return permission, nil
+}
+
+func (s *rbacService) CreatePermission(ctx context.Context, name string) (*entity.Permission, error) {
+	name, err := validatePermissionName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensurePermissionNameFree(ctx, name); err != nil {
+		return nil, err
+	}
+
+	permission := &entity.Permission{Name: name}
+	if err := s.repo.CreatePermission(ctx, permission); err != nil {
+		return nil, writeError(err, "Permission already exists")
+	}
+
+	return permission, nil
+}
+
+func (s *rbacService) UpdatePermission(ctx context.Context, id, name string) (*entity.Permission, error) {
+	name, err := validatePermissionName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	permission, err := s.repo.FindPermissionByID(ctx, id)
+	if err != nil {
+		return nil, lookupError(err, "Permission not found")
+	}
+	if permission.Name == name {
+		return permission, nil
+	}
+
+	if err := s.ensurePermissionNameFree(ctx, name); err != nil {
+		return nil, err
+	}
+
+	permission.Name = name
+	if err := s.repo.UpdatePermission(ctx, permission); err != nil {
+		return nil, writeError(err, "Permission already exists")
+	}
+
+	s.rbacChanged()
+	return permission, nil
+}
+
+func (s *rbacService) DeletePermission(ctx context.Context, id string) error {
+	permission, err := s.repo.FindPermissionByID(ctx, id)
+	if err != nil {
+		return lookupError(err, "Permission not found")
+	}
+
+	if err := s.repo.DeletePermission(ctx, permission); err != nil {
+		return err
+	}
+
+	s.rbacChanged()
+	return nil
+}
+
+func (s *rbacService) SyncPermissions(ctx context.Context, required []string) (*PermissionSyncReport, error) {
+	required = uniqueNames(required)
+	for _, name := range required {
+		if _, err := validatePermissionName(name); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &PermissionSyncReport{}
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.FindAllPermissions(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range missingPermissions(required, existing) {
+			if err := s.repo.CreatePermission(ctx, &entity.Permission{Name: name}); err != nil {
+				return writeError(err, "Permission already exists")
+			}
+			report.Created = append(report.Created, name)
+		}
+
+		// Wildcards such as roles:* or *:* are in use while they cover a route
+		for _, permission := range existing {
+			if !coversAny(permission.Name, required) {
+				report.Orphaned = append(report.Orphaned, permission.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Assignments
+
+func (s *rbacService) AssignRoleToUser(ctx context.Context, userID, roleName string, grant RoleGrant) error {
+	now := time.Now()
+	if grant.ExpiresAt != nil {
+		if !grant.ExpiresAt.After(now) {
+			return invalid("Expiry must be in the future")
+		}
+		if grant.StartsAt != nil && !grant.ExpiresAt.After(*grant.StartsAt) {
+			return invalid("Expiry must be after the start time")
+		}
+	}
+
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		user, role, err := s.findUserAndRole(ctx, userID, roleName)
+		if err != nil {
+			return err
+		}
+
+		// Re-assigning with a window replaces the existing one
+		if user.HasRole(role.Name) && !grant.timeBound() {
+			return conflict("User already has this role")
+		}
+
+		// A window on admin could leave nobody holding it once it closes
+		if role.Name == entity.RoleAdmin && grant.timeBound() {
+			last, err := s.lastPermanentAdmin(ctx, role, user.ID)
+			if err != nil {
+				return err
+			}
+			if last {
+				return conflict("Cannot make the admin role of the last permanent admin time-bound")
+			}
+		}
+
+		assignment := &entity.UserRole{
+			UserID:    user.ID,
+			RoleID:    role.ID,
+			GrantedAt: now,
+			StartsAt:  grant.StartsAt,
+			ExpiresAt: grant.ExpiresAt,
+		}
+		if grant.GrantedBy != "" {
+			assignment.GrantedBy = &grant.GrantedBy
+		}
+
+		return s.repo.AttachRole(ctx, assignment)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userChanged(userID)
+	return nil
+}
+
+func (s *rbacService) RevokeRoleFromUser(ctx context.Context, userID, roleName string) error {
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		user, role, err := s.findUserAndRole(ctx, userID, roleName)
+		if err != nil {
+			return err
+		}
+
+		if !user.HasRole(role.Name) {
+			return notFound("User does not have this role")
+		}
+
+		if role.Name == entity.RoleAdmin {
+			last, err := s.lastPermanentAdmin(ctx, role, user.ID)
+			if err != nil {
+				return err
+			}
+			if last {
+				return conflict("Cannot revoke the admin role from the last admin")
+			}
+		}
+
+		return s.repo.DetachRole(ctx, user, role)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userChanged(userID)
+	return nil
+}
+
+func (s *rbacService) AssignPermissionToRole(ctx context.Context, roleName, permissionName string) (*entity.Role, error) {
+	var role *entity.Role
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var permission *entity.Permission
+		var err error
+		role, permission, err = s.findRoleAndPermission(ctx, roleName, permissionName)
+		if err != nil {
+			return err
+		}
+
+		if role.HasPermission(permission.Name) {
+			return conflict("Role already has this permission")
+		}
+
+		return s.repo.AttachPermission(ctx, role, permission)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.rbacChanged()
+	return role, nil
+}
+
+func (s *rbacService) RevokePermissionFromRole(ctx context.Context, roleName, permissionName string) (*entity.Role, error) {
+	var role *entity.Role
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var permission *entity.Permission
+		var err error
+		role, permission, err = s.findRoleAndPermission(ctx, roleName, permissionName)
+		if err != nil {
+			return err
+		}
+
+		if !role.HasPermission(permission.Name) {
+			return notFound("Role does not have this permission")
+		}
+
+		return s.repo.DetachPermission(ctx, role, permission)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.rbacChanged()
+	return role, nil
+}
+
+func (s *rbacService) GrantUserPermission(ctx context.Context, userID, permissionName, effect string) (*entity.UserPermission, error) {
+	if effect == "" {
+		effect = entity.PermissionEffectAllow
+	}
+	if effect != entity.PermissionEffectAllow && effect != entity.PermissionEffectDeny {
+		return nil, invalid("Effect must be either allow or deny")
+	}
+
+	var userPermission *entity.UserPermission
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		user, err := s.repo.FindUserWithRoles(ctx, userID)
+		if err != nil {
+			return lookupError(err, "User not found")
+		}
+
+		permission, err := s.repo.FindPermissionByName(ctx, permissionName)
+		if err != nil {
+			return lookupError(err, "Permission not found")
+		}
+
+		userPermission = &entity.UserPermission{
+			UserID:       user.ID,
+			PermissionID: permission.ID,
+			Effect:       effect,
+		}
+		if err := s.repo.SaveUserPermission(ctx, userPermission); err != nil {
+			return err
+		}
+		userPermission.Permission = permission
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.userChanged(userID)
+	return userPermission, nil
+}
+
+func (s *rbacService) RevokeUserPermission(ctx context.Context, userID, permissionName string) error {
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		permission, err := s.repo.FindPermissionByName(ctx, permissionName)
+		if err != nil {
+			return lookupError(err, "Permission not found")
+		}
+
+		deleted, err := s.repo.DeleteUserPermission(ctx, userID, permission.ID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return notFound("User has no direct entry for this permission")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userChanged(userID)
+	return nil
+}
+
+func (s *rbacService) LoadPrincipal(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := s.repo.FindPrincipal(ctx, userID, time.Now())
+	if err != nil {
+		return nil, lookupError(err, "User not found")
+	}
+
+	graph, err := linkRoleGraph(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	linkRoles(user.Roles, graph)
+	for _, membership := range user.Memberships {
+		linkRoles(membership.Roles, graph)
+	}
+
+	user.Groups, err = s.loadUserGroups(ctx, user, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// loadUserGroups returns the user's groups in organizations they are an
+// active member of, linked to their parent groups and the role graph.
+func (s *rbacService) loadUserGroups(ctx context.Context, user *entity.User, roles map[string]*entity.Role) ([]*entity.Group, error) {
+	groupIDs, err := s.repo.FindUserGroupIDs(ctx, user.ID)
+	if err != nil || len(groupIDs) == 0 {
+		return nil, err
+	}
+
+	organizationIDs := make([]string, 0, len(user.Memberships))
+	for _, membership := range user.Memberships {
+		organizationIDs = append(organizationIDs, membership.OrganizationID)
+	}
+
+	groups, err := s.repo.FindGroupGraph(ctx, organizationIDs)
+	if err != nil {
+		return nil, err
+	}
+	graph := linkGroups(groups)
+
+	var userGroups []*entity.Group
+	for _, group := range groups {
+		linkRoles(group.Roles, roles)
+	}
+	for _, id := range groupIDs {
+		if group, ok := graph[id]; ok {
+			userGroups = append(userGroups, group)
+		}
+	}
+	return userGroups, nil
+}
+
+// Expiry
+
+func (s *rbacService) SweepExpiredRoles(ctx context.Context) (int64, error) {
+	removed, err := s.repo.DeleteExpiredRoleAssignments(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	// The sweep does not report whose roles went, so drop every cached principal
+	if removed > 0 {
+		s.rbacChanged()
+	}
+	return removed, nil
+}
+
+func (s *rbacService) NotifyExpiringRoles(ctx context.Context, within time.Duration) (int, error) {
+	now := time.Now()
+	assignments, err := s.repo.FindExpiringRoleAssignments(ctx, now, now.Add(within))
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, assignment := range assignments {
+		if err := s.mailer.SendRoleExpiryEmail(assignment.Email, assignment.RoleName, assignment.ExpiresAt, assignment.Locale); err != nil {
+			// Left unmarked so the next run retries
+			slog.Error("Failed to send role expiry email", "error", err, "user_id", assignment.UserID, "role", assignment.RoleName)
+			continue
+		}
+		if err := s.repo.MarkExpiryNotified(ctx, assignment.UserID, assignment.RoleID, now); err != nil {
+			return notified, err
+		}
+		notified++
+	}
+
+	return notified, nil
+}
+
+// Helpers
+
+func (s *rbacService) userChanged(userID string) {
+	s.bus.Publish(events.Event{Name: events.UserChanged, UserID: userID})
+}
+
+func (s *rbacService) rbacChanged() {
+	s.bus.Publish(events.Event{Name: events.RBACChanged})
+}
+
+// linkRoleGraph loads every role keyed by ID, with each Parents slice pointing
+// at the shared role values so the hierarchy can be walked to any depth.
+func linkRoleGraph(ctx context.Context, repo repository.RoleRepository) (map[string]*entity.Role, error) {
+	roles, err := repo.FindRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := make(map[string]*entity.Role, len(roles))
+	for _, role := range roles {
+		graph[role.ID] = role
+	}
+
+	for _, role := range roles {
+		parents := make([]*entity.Role, 0, len(role.Parents))
+		for _, parent := range role.Parents {
+			if linked, ok := graph[parent.ID]; ok {
+				parents = append(parents, linked)
+			}
+		}
+		role.Parents = parents
+	}
+
+	return graph, nil
+}
+
+// linkGroups points each group's Parent at the shared parent value and returns
+// the groups keyed by ID.
+func linkGroups(groups []*entity.Group) map[string]*entity.Group {
+	graph := make(map[string]*entity.Group, len(groups))
+	for _, group := range groups {
+		graph[group.ID] = group
+	}
+	for _, group := range groups {
+		group.Parent = nil
+		if group.ParentID != nil {
+			group.Parent = graph[*group.ParentID]
+		}
+	}
+	return graph
+}
+
+// linkRoles swaps each role for its linked copy in the graph.
+func linkRoles(roles []*entity.Role, graph map[string]*entity.Role) {
+	for i, role := range roles {
+		if linked, ok := graph[role.ID]; ok {
+			roles[i] = linked
+		}
+	}
+}
+
+// lastPermanentAdmin reports whether no user but userID holds the admin role
+// without an expiry. It locks the role first, so two concurrent changes
+// cannot both count the other's admin.
+func (s *rbacService) lastPermanentAdmin(ctx context.Context, role *entity.Role, userID string) (bool, error) {
+	if err := s.repo.LockRole(ctx, role); err != nil {
+		return false, err
+	}
+	others, err := s.repo.CountPermanentRoleHolders(ctx, role.ID, userID, time.Now())
+	return others == 0, err
+}
+
+func (s *rbacService) findUserAndRole(ctx context.Context, userID, roleName string) (*entity.User, *entity.Role, error) {
+	user, err := s.repo.FindUserWithRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, lookupError(err, "User not found")
+	}
+
+	role, err := s.repo.FindRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, nil, lookupError(err, "Role not found")
+	}
+
+	return user, role, nil
+}
+
+func (s *rbacService) findRoleAndPermission(ctx context.Context, roleName, permissionName string) (*entity.Role, *entity.Permission, error) {
+	role, err := s.repo.FindRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, nil, lookupError(err, "Role not found")
+	}
+
+	permission, err := s.repo.FindPermissionByName(ctx, permissionName)
+	if err != nil {
+		return nil, nil, lookupError(err, "Permission not found")
+	}
+
+	return role, permission, nil
+}
+
+func (s *rbacService) ensureRoleNameFree(ctx context.Context, name string) error {
+	_, err := s.repo.FindRoleByName(ctx, name)
+	if err == nil {
+		return conflict("Role already exists")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *rbacService) ensurePermissionNameFree(ctx context.Context, name string) error {
+	_, err := s.repo.FindPermissionByName(ctx, name)
+	if err == nil {
+		return conflict("Permission already exists")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func validateRBACName(kind, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", invalid(kind + " name is required")
+	}
+	if len(name) > maxRBACNameLength {
+		return "", invalid(fmt.Sprintf("%s name must be at most %d characters", kind, maxRBACNameLength))
+	}
+	return name, nil
+}
+
+func validatePermissionName(name string) (string, error) {
+	name, err := validateRBACName("Permission", name)
+	if err != nil {
+		return "", err
+	}
+	if !entity.ValidPermissionName(name) {
+		return "", invalid("Permission name must use the resource:action format, e.g. users:read")
+	}
+	return name, nil
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+func coversAny(pattern string, names []string) bool {
+	for _, name := range names {
+		if entity.MatchPermission(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func missingPermissions(names []string, permissions []*entity.Permission) []string {
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
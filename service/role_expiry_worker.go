@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// StartRoleExpiryWorker sweeps expired role assignments and sends expiry
+// notices every interval until the returned stop function is called. A
+// non-positive interval disables the worker.
+func StartRoleExpiryWorker(rbac RBACService, interval, notice time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	// Stopping cancels the context, which also aborts a sweep in progress
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			runRoleExpiryCycle(ctx, rbac, notice)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return cancel
+}
+
+func runRoleExpiryCycle(ctx context.Context, rbac RBACService, notice time.Duration) {
+	removed, err := rbac.SweepExpiredRoles(ctx)
+	if err != nil {
+		slog.Error("Failed to sweep expired roles", "error", err)
+	} else if removed > 0 {
+		slog.Info("Expired role assignments removed", "count", removed)
+	}
+
+	if notice <= 0 {
+		return
+	}
+	notified, err := rbac.NotifyExpiringRoles(ctx, notice)
+	if err != nil {
+		slog.Error("Failed to send role expiry notices", "error", err)
+	} else if notified > 0 {
+		slog.Info("Role expiry notices sent", "count", notified)
+	}
+}
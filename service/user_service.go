@@ -2,91 +2,189 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
+	"golang-backend/apperror"
+	"golang-backend/config"
 	"golang-backend/dto"
 	"golang-backend/entity"
+	"golang-backend/events"
 	"golang-backend/repository"
 	"golang-backend/utils"
 	"image/png"
 	"time"
 
 	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
-	Login(req dto.UserLoginRequest) (string, error)
-	Register(req dto.UserRegisterRequest) (*dto.UserResponse, error)
-	VerifyEmail(req dto.VerifyEmailRequest) error
-	ForgotPassword(email string) error
-	ResetPassword(req dto.ResetPasswordRequest) error
-	ResendVerificationCode(email string) error
-	ResendResetPasswordCode(email string) error
-	GetUsers(filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
-	Setup2FA(userID string) (*dto.Setup2FAResponse, error)
-	Verify2FA(userID string, code string) error
-	GetMe(userID string) (*dto.UserResponse, error)
+	Login(ctx context.Context, req dto.UserLoginRequest) (string, error)
+	Register(ctx context.Context, req dto.UserRegisterRequest) (*dto.UserResponse, error)
+	VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
+	ResendVerificationCode(ctx context.Context, email string) error
+	ResendResetPasswordCode(ctx context.Context, email string) error
+	GetUsers(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error)
+	Setup2FA(ctx context.Context, userID string) (*dto.Setup2FAResponse, error)
+	Verify2FA(ctx context.Context, userID string, code string) error
+	GetMe(ctx context.Context, userID string) (*dto.UserResponse, error)
+	// GetUser loads the user entity so callers can run resource policies on it.
+	// A non-empty organizationID hides users outside that organization.
+	GetUser(ctx context.Context, id, organizationID string) (*entity.User, error)
+	GetUserWithRoles(ctx context.Context, id, organizationID string) (*entity.User, error)
+	UpdateUser(ctx context.Context, user *entity.User, req dto.UpdateUserRequest) (*dto.UserResponse, error)
 }
 
+// errTwoFADisabled answers 2FA setup while ENABLE_TWO_FACTOR_AUTH is off.
+// Users who already enabled 2FA must still pass it to log in.
+var errTwoFADisabled = apperror.Forbidden.New("Two-factor authentication is disabled")
+
 type userService struct {
-	repo repository.UserRepository
+	repo   repository.UserRepository
+	roles  repository.RoleRepository
+	tx     repository.TransactionManager
+	bus    *events.Bus
+	audit  AuditService
+	tokens *utils.TokenManager
+	mailer *utils.Mailer
+	config *config.Live
+}
+
+// features reads the flags on every use so a config reload applies to the
+// next request.
+func (s *userService) features() config.FeatureFlags {
+	return s.config.Get().Features
+}
+
+func (s *userService) GetMe(ctx context.Context, userID string) (*dto.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, lookupError(err, "User not found")
+	}
+
+	return dto.NewUserResponse(user), nil
+}
+
+func (s *userService) GetUser(ctx context.Context, id, organizationID string) (*entity.User, error) {
+	user, err := s.repo.FindMemberByID(ctx, id, organizationID)
+	if err != nil {
+		return nil, lookupError(err, "User not found")
+	}
+	return user, nil
 }
 
-func (s *userService) GetMe(userID string) (*dto.UserResponse, error) {
-	user, err := s.repo.FindByID(userID)
+// GetUserWithRoles is GetUser with the user's roles linked to the role
+// hierarchy, for policies that compare the rank of the target.
+func (s *userService) GetUserWithRoles(ctx context.Context, id, organizationID string) (*entity.User, error) {
+	user, err := s.GetUser(ctx, id, organizationID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.UserResponse{
-		ID:             user.ID,
-		Name:           user.Name,
-		Email:          user.Email,
-		IsTwoFAEnabled: user.IsTwoFAEnabled,
-	}, nil
+	withRoles, err := s.roles.FindUserWithRoles(ctx, user.ID)
+	if err != nil {
+		return nil, lookupError(err, "User not found")
+	}
+	graph, err := linkRoleGraph(ctx, s.roles)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Roles = withRoles.Roles
+	linkRoles(user.Roles, graph)
+	return user, nil
+}
+
+func (s *userService) UpdateUser(ctx context.Context, user *entity.User, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if req.Department != nil {
+		user.Department = *req.Department
+	}
+	if req.Locale != nil {
+		user.Locale = *req.Locale
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Department feeds resource policies, so cached principals must reload
+	s.bus.Publish(events.Event{Name: events.UserChanged, UserID: user.ID})
+	return dto.NewUserResponse(user), nil
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(
+	repo repository.UserRepository,
+	roles repository.RoleRepository,
+	tx repository.TransactionManager,
+	bus *events.Bus,
+	audit AuditService,
+	tokens *utils.TokenManager,
+	mailer *utils.Mailer,
+	live *config.Live,
+) UserService {
+	return &userService{repo: repo, roles: roles, tx: tx, bus: bus, audit: audit, tokens: tokens, mailer: mailer, config: live}
 }
 
-func (s *userService) GetUsers(filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
-	return s.repo.Paginate(filters, page, perPage)
+func (s *userService) GetUsers(ctx context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	return s.repo.Paginate(ctx, filters, page, perPage)
 }
 
-func (s *userService) Login(req dto.UserLoginRequest) (string, error) {
-	user, err := s.repo.FindByEmail(req.Email)
+func (s *userService) Login(ctx context.Context, req dto.UserLoginRequest) (string, error) {
+	user, err := s.repo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		s.audit.Record(ctx, AuditEntry{Action: entity.AuditLoginFailed, After: map[string]string{"email": req.Email, "reason": "unknown email"}})
+		return "", unauthorized("Invalid email or password")
 	}
 
-	if !user.IsVerified {
-		return "", errors.New("email not verified")
+	if s.features().EmailVerification && !user.IsVerified {
+		s.loginFailed(ctx, user, "email not verified")
+		return "", apperror.Forbidden.New("Email not verified")
 	}
 
 	err = utils.CheckPassword(req.Password, user.Password)
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		s.loginFailed(ctx, user, "wrong password")
+		return "", unauthorized("Invalid email or password")
 	}
 
 	if user.IsTwoFAEnabled {
 		if req.TwoFACode == "" {
-			return "", errors.New("2FA code required")
+			return "", unauthorized("2FA code required")
 		}
 		if !totp.Validate(req.TwoFACode, user.TwoFASecret) {
-			return "", errors.New("invalid 2FA code")
+			s.loginFailed(ctx, user, "invalid 2FA code")
+			return "", unauthorized("Invalid 2FA code")
 		}
 	}
 
-	token, err := utils.GenerateToken(user.ID)
+	token, err := s.tokens.GenerateToken(user.ID)
 	if err != nil {
 		return "", err
 	}
 
+	s.audit.Record(ctx, AuditEntry{ActorID: user.ID, Action: entity.AuditLoginSucceeded, TargetType: "user", TargetID: user.ID})
 	return token, nil
 }
 
-func (s *userService) Register(req dto.UserRegisterRequest) (*dto.UserResponse, error) {
+func (s *userService) loginFailed(ctx context.Context, user *entity.User, reason string) {
+	s.audit.Record(ctx, AuditEntry{
+		Action:     entity.AuditLoginFailed,
+		TargetType: "user",
+		TargetID:   user.ID,
+		After:      map[string]string{"reason": reason},
+	})
+}
+
+func (s *userService) Register(ctx context.Context, req dto.UserRegisterRequest) (*dto.UserResponse, error) {
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, err
@@ -99,17 +197,38 @@ func (s *userService) Register(req dto.UserRegisterRequest) (*dto.UserResponse,
 		Email:            req.Email,
 		Password:         hashedPassword,
 		VerificationCode: code,
-		IsVerified:       false,
+		IsVerified:       !s.features().EmailVerification,
+		Locale:           req.Locale,
+	}
+	if user.IsVerified {
+		user.VerificationCode = ""
 	}
 
-	if err := s.repo.Create(user); err != nil {
+	// The user is only created together with their default role
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return writeError(err, "Email is already registered")
+		}
+		return s.grantDefaultRole(ctx, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    user.ID,
+		Action:     entity.AuditRegistered,
+		TargetType: "user",
+		TargetID:   user.ID,
+		After:      map[string]string{"name": user.Name, "email": user.Email},
+	})
+
 	// Send email asynchronously
-	go func() {
-		_ = utils.SendVerificationEmail(user.Email, code)
-	}()
+	if !user.IsVerified {
+		go func() {
+			_ = s.mailer.SendVerificationEmail(user.Email, code, user.Locale)
+		}()
+	}
 
 	return &dto.UserResponse{
 		ID:    user.ID,
@@ -118,60 +237,87 @@ func (s *userService) Register(req dto.UserRegisterRequest) (*dto.UserResponse,
 	}, nil
 }
 
-func (s *userService) VerifyEmail(req dto.VerifyEmailRequest) error {
-	user, err := s.repo.FindByEmail(req.Email)
+// grantDefaultRole gives a new user the DEFAULT_ROLE, if one is set.
+func (s *userService) grantDefaultRole(ctx context.Context, user *entity.User) error {
+	name := s.config.Get().RBAC.DefaultRole
+	if name == "" {
+		return nil
+	}
+
+	role, err := s.roles.FindRoleByName(ctx, name)
 	if err != nil {
-		return errors.New("user not found")
+		return lookupError(err, "Default role not found, run the seeder")
+	}
+	return s.roles.AttachRole(ctx, &entity.UserRole{UserID: user.ID, RoleID: role.ID, GrantedAt: time.Now()})
+}
+
+func (s *userService) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error {
+	user, err := s.repo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		return lookupError(err, "User not found")
 	}
 
 	if user.IsVerified {
-		return errors.New("email already verified")
+		return conflict("Email already verified")
 	}
 
 	if user.VerificationCode != req.Code {
-		return errors.New("invalid verification code")
+		return invalid("Invalid verification code")
 	}
 
 	user.IsVerified = true
 	user.VerificationCode = ""
 
-	return s.repo.Update(user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditEntry{
+		Action:     entity.AuditEmailVerified,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     map[string]bool{"is_verified": false},
+		After:      map[string]bool{"is_verified": true},
+	})
+	return nil
 }
 
-func (s *userService) ForgotPassword(email string) error {
-	user, err := s.repo.FindByEmail(email)
+func (s *userService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
 	if err != nil {
-		return errors.New("user not found")
+		return lookupError(err, "User not found")
 	}
 
 	code := utils.GenerateRandomCode(6)
 	user.ResetToken = code
 	user.ResetTokenExpiry = time.Now().Add(15 * time.Minute)
 
-	if err := s.repo.Update(user); err != nil {
+	if err := s.repo.Update(ctx, user); err != nil {
 		return err
 	}
 
+	s.audit.Record(ctx, AuditEntry{Action: entity.AuditPasswordResetRequest, TargetType: "user", TargetID: user.ID})
+
 	// Send email asynchronously
 	go func() {
-		_ = utils.SendResetPasswordEmail(user.Email, code)
+		_ = s.mailer.SendResetPasswordEmail(user.Email, code, user.Locale)
 	}()
 
 	return nil
 }
 
-func (s *userService) ResetPassword(req dto.ResetPasswordRequest) error {
-	user, err := s.repo.FindByEmail(req.Email)
+func (s *userService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
+	user, err := s.repo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		return errors.New("user not found")
+		return lookupError(err, "User not found")
 	}
 
 	if user.ResetToken != req.Code {
-		return errors.New("invalid or expired reset code")
+		return invalid("Invalid or expired reset code")
 	}
 
 	if time.Now().After(user.ResetTokenExpiry) {
-		return errors.New("reset code expired")
+		return invalid("Reset code expired")
 	}
 
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
@@ -183,43 +329,52 @@ func (s *userService) ResetPassword(req dto.ResetPasswordRequest) error {
 	user.ResetToken = ""
 	user.ResetTokenExpiry = time.Time{}
 
-	return s.repo.Update(user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditEntry{Action: entity.AuditPasswordReset, TargetType: "user", TargetID: user.ID})
+	return nil
 }
 
-func (s *userService) ResendVerificationCode(email string) error {
-	user, err := s.repo.FindByEmail(email)
+func (s *userService) ResendVerificationCode(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
 	if err != nil {
-		return errors.New("user not found")
+		return lookupError(err, "User not found")
 	}
 
 	if user.IsVerified {
-		return errors.New("email already verified")
+		return conflict("Email already verified")
 	}
 
 	code := utils.GenerateRandomCode(6)
 	user.VerificationCode = code
 
-	if err := s.repo.Update(user); err != nil {
+	if err := s.repo.Update(ctx, user); err != nil {
 		return err
 	}
 
 	// Send email asynchronously
 	go func() {
-		_ = utils.SendVerificationEmail(user.Email, code)
+		_ = s.mailer.SendVerificationEmail(user.Email, code, user.Locale)
 	}()
 
 	return nil
 }
 
-func (s *userService) ResendResetPasswordCode(email string) error {
+func (s *userService) ResendResetPasswordCode(ctx context.Context, email string) error {
 	// Functionally same as ForgotPassword
-	return s.ForgotPassword(email)
+	return s.ForgotPassword(ctx, email)
 }
 
-func (s *userService) Setup2FA(userID string) (*dto.Setup2FAResponse, error) {
-	user, err := s.repo.FindByID(userID)
+func (s *userService) Setup2FA(ctx context.Context, userID string) (*dto.Setup2FAResponse, error) {
+	if !s.features().TwoFactorAuth {
+		return nil, errTwoFADisabled
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, lookupError(err, "User not found")
 	}
 
 	key, err := totp.Generate(totp.GenerateOpts{
@@ -239,30 +394,48 @@ func (s *userService) Setup2FA(userID string) (*dto.Setup2FAResponse, error) {
 	qrCodeURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
 
 	user.TwoFASecret = key.Secret()
-	if err := s.repo.Update(user); err != nil {
+	if err := s.repo.Update(ctx, user); err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, AuditEntry{Action: entity.AuditTwoFASetup, TargetType: "user", TargetID: user.ID})
+
 	return &dto.Setup2FAResponse{
 		Secret:    key.Secret(),
 		QRCodeURL: qrCodeURL,
 	}, nil
 }
 
-func (s *userService) Verify2FA(userID string, code string) error {
-	user, err := s.repo.FindByID(userID)
+func (s *userService) Verify2FA(ctx context.Context, userID string, code string) error {
+	if !s.features().TwoFactorAuth {
+		return errTwoFADisabled
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
 	if err != nil {
-		return errors.New("user not found")
+		return lookupError(err, "User not found")
 	}
 
 	if user.TwoFASecret == "" {
-		return errors.New("2FA not setup")
+		return apperror.BadRequest.New("2FA not setup")
 	}
 
 	if !totp.Validate(code, user.TwoFASecret) {
-		return errors.New("invalid code")
+		return invalid("Invalid 2FA code")
 	}
 
+	wasEnabled := user.IsTwoFAEnabled
 	user.IsTwoFAEnabled = true
-	return s.repo.Update(user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditEntry{
+		Action:     entity.AuditTwoFAEnabled,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     map[string]bool{"is_two_fa_enabled": wasEnabled},
+		After:      map[string]bool{"is_two_fa_enabled": true},
+	})
+	return nil
 }
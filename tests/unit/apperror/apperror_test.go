@@ -0,0 +1,82 @@
+package apperror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang-backend/apperror"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestFrom(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		code    apperror.Code
+		status  int
+		message string
+	}{
+		{"record not found", fmt.Errorf("find user: %w", gorm.ErrRecordNotFound), apperror.NotFound, http.StatusNotFound, "Resource not found"},
+		{"gorm duplicated key", gorm.ErrDuplicatedKey, apperror.Conflict, http.StatusConflict, "Resource already exists"},
+		{"pg unique violation", &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint \"idx_users_email\""}, apperror.Conflict, http.StatusConflict, "Resource already exists"},
+		{"pg foreign key violation", &pgconn.PgError{Code: "23503"}, apperror.Conflict, http.StatusConflict, "Resource is still referenced or refers to a missing resource"},
+		{"request deadline", fmt.Errorf("find user: %w", context.DeadlineExceeded), apperror.Timeout, http.StatusServiceUnavailable, "Request timed out"},
+		{"pg statement timeout", &pgconn.PgError{Code: "57014"}, apperror.Timeout, http.StatusServiceUnavailable, "Request timed out"},
+		{"client gone", context.Canceled, apperror.Timeout, http.StatusServiceUnavailable, "Request was cancelled"},
+		{"unknown error", errors.New("connection refused"), apperror.Internal, http.StatusInternalServerError, "Internal server error"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := apperror.From(tc.err)
+			if got.Code != tc.code || got.Status() != tc.status || got.Message != tc.message {
+				t.Fatalf("From() = %s %d %q, want %s %d %q", got.Code, got.Status(), got.Message, tc.code, tc.status, tc.message)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Fatal("the internal cause should stay reachable with errors.Is")
+			}
+		})
+	}
+}
+
+func TestFromKeepsApplicationErrors(t *testing.T) {
+	original := apperror.Forbidden.New("Forbidden: insufficient permissions")
+	wrapped := fmt.Errorf("update user: %w", original)
+
+	if got := apperror.From(wrapped); got != original {
+		t.Fatalf("From() = %v, want the wrapped application error", got)
+	}
+	if apperror.From(nil) != nil {
+		t.Fatal("From(nil) should be nil")
+	}
+}
+
+func TestErrorIsMatchesCode(t *testing.T) {
+	err := fmt.Errorf("assign role: %w", apperror.Conflict.Wrap(gorm.ErrDuplicatedKey, "Role already assigned"))
+
+	if !errors.Is(err, apperror.Conflict) {
+		t.Fatal("expected errors.Is to match the Conflict code")
+	}
+	if errors.Is(err, apperror.NotFound) {
+		t.Fatal("did not expect errors.Is to match another code")
+	}
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatal("expected errors.Is to reach the cause")
+	}
+}
+
+func TestBindKeepsFieldDetails(t *testing.T) {
+	err := apperror.Bind(errors.New("Key: 'UserLoginRequest.Email' Error:Field validation for 'Email' failed on the 'required' tag"))
+
+	if err.Code != apperror.Validation || err.Status() != http.StatusBadRequest {
+		t.Fatalf("Bind() = %s %d, want validation 400", err.Code, err.Status())
+	}
+	if err.Details == nil {
+		t.Fatal("expected the binding message as details")
+	}
+}
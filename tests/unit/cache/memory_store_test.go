@@ -0,0 +1,50 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang-backend/cache"
+)
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := cache.NewMemoryStore(2)
+	store.Set("a", []byte("1"), time.Minute)
+	store.Set("b", []byte("2"), time.Minute)
+	store.Get("a")
+	store.Set("c", []byte("3"), time.Minute)
+
+	if _, err := store.Get("b"); !errors.Is(err, cache.ErrMiss) {
+		t.Error("Least recently used entry should be evicted")
+	}
+	if value, err := store.Get("a"); err != nil || string(value) != "1" {
+		t.Errorf("Recently used entry should survive, got %q (%v)", value, err)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected store bounded to 2 entries, got %d", store.Len())
+	}
+}
+
+func TestMemoryStore_ExpiresEntries(t *testing.T) {
+	store := cache.NewMemoryStore(10)
+	store.Set("a", []byte("1"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := store.Get("a"); !errors.Is(err, cache.ErrMiss) {
+		t.Error("Expired entry should be a miss")
+	}
+}
+
+func TestMemoryStore_DeletePrefix(t *testing.T) {
+	store := cache.NewMemoryStore(10)
+	store.Set("principal:1", []byte("1"), time.Minute)
+	store.Set("principal:2", []byte("2"), time.Minute)
+	store.Set("other", []byte("3"), time.Minute)
+
+	store.DeletePrefix("principal:")
+
+	if store.Len() != 1 {
+		t.Errorf("Expected only the unrelated key to remain, got %d entries", store.Len())
+	}
+}
@@ -0,0 +1,141 @@
+package cache_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-backend/cache"
+)
+
+// fakeRedis serves the handful of commands RedisStore sends, ignoring TTLs.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func startFakeRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeRedis{data: map[string]string{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		io.WriteString(conn, f.handle(args))
+	}
+}
+
+func (f *fakeRedis) handle(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "SET":
+		f.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "GET":
+		value, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := f.data[key]; ok {
+				delete(f.data, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "SCAN":
+		prefix := strings.TrimSuffix(args[3], "*")
+		var keys []string
+		for key := range f.data {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, fmt.Sprintf("$%d\r\n%s\r\n", len(key), key))
+			}
+		}
+		return fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n%s", len(keys), strings.Join(keys, ""))
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		args[i] = string(value[:size])
+	}
+	return args, nil
+}
+
+func TestRedisStore_RoundTrip(t *testing.T) {
+	store := cache.NewRedisStore(startFakeRedis(t), "", 0)
+
+	if _, err := store.Get("missing"); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Expected miss for unknown key, got %v", err)
+	}
+
+	value := "{\"ID\":\"u1\"}\r\nwith newline"
+	if err := store.Set("principal:u1", []byte(value), time.Minute); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	got, err := store.Get("principal:u1")
+	if err != nil || string(got) != value {
+		t.Errorf("Expected %q, got %q (%v)", value, got, err)
+	}
+
+	store.Set("principal:u2", []byte("2"), time.Minute)
+	store.Set("session:u1", []byte("3"), time.Minute)
+	if err := store.DeletePrefix("principal:"); err != nil {
+		t.Fatalf("Failed to delete prefix: %v", err)
+	}
+	if _, err := store.Get("principal:u2"); !errors.Is(err, cache.ErrMiss) {
+		t.Error("Prefixed keys should be deleted")
+	}
+	if _, err := store.Get("session:u1"); err != nil {
+		t.Errorf("Unrelated keys should survive: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-backend/config"
+	"golang-backend/secrets"
+)
+
+func load(t *testing.T, args ...string) (*config.Config, error) {
+	t.Helper()
+	// Required and without a default
+	if _, ok := os.LookupEnv("CORS_ALLOWED_ORIGINS"); !ok {
+		t.Setenv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
+	}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := config.NewLoader(flags)
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	return loader.Load()
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayersSources(t *testing.T) {
+	path := writeFile(t, `
+server:
+  port: 9000
+log:
+  level: warn
+database:
+  host: db.internal
+  conn_max_lifetime: 30m
+cors:
+  allowed_origins: [https://app.example.com]
+`)
+	t.Setenv("DB_HOST", "db.from-env")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("PRINCIPAL_CACHE_TTL", "5m")
+
+	cfg, err := load(t, "-config", path, "-log-level", "debug")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Server.Port != 9000 {
+		t.Errorf("port = %d, want the file value 9000", cfg.Server.Port)
+	}
+	if cfg.Database.ConnMaxLifetime != 30*time.Minute {
+		t.Errorf("conn_max_lifetime = %s, want the file value 30m", cfg.Database.ConnMaxLifetime)
+	}
+	if cfg.Database.Host != "db.from-env" {
+		t.Errorf("host = %q, want the environment to override the file", cfg.Database.Host)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, " "); got != "https://a.example.com https://b.example.com" {
+		t.Errorf("allowed origins = %q", got)
+	}
+	if cfg.PrincipalCache.TTL != 5*time.Minute {
+		t.Errorf("cache ttl = %s, want 5m", cfg.PrincipalCache.TTL)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("log level = %q, want the flag to override the file", cfg.Log.Level)
+	}
+	if cfg.RateLimit.IPBurst != 10 {
+		t.Errorf("ip burst = %d, want the default 10", cfg.RateLimit.IPBurst)
+	}
+}
+
+func TestLoadReadsOldSMTPPassName(t *testing.T) {
+	t.Setenv("SMTP_PASS", "old-name")
+
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.SMTP.Password != "old-name" {
+		t.Fatalf("smtp password = %q, want SMTP_PASS to be read", cfg.SMTP.Password)
+	}
+
+	t.Setenv("SMTP_PASSWORD", "new-name")
+	if cfg, _ = load(t); cfg.SMTP.Password != "new-name" {
+		t.Fatalf("smtp password = %q, want SMTP_PASSWORD to win", cfg.SMTP.Password)
+	}
+}
+
+func TestLoadFailsFast(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("JWT_SECRET", "short")
+	t.Setenv("DB_PORT", "five")
+	t.Setenv("PRINCIPAL_CACHE_BACKEND", "memcached")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+
+	if _, err := load(t); err == nil || !strings.Contains(err.Error(), `DB_PORT: invalid integer "five"`) {
+		t.Fatalf("Load() error = %v, want the unparsable DB_PORT named", err)
+	}
+
+	t.Setenv("DB_PORT", "5432")
+	_, err := load(t)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+	for _, want := range []string{"JWT_SECRET: must be at least 32 characters", "PRINCIPAL_CACHE_BACKEND: must be memory, redis or none", "CORS_ALLOWED_ORIGINS: must be set"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestMetricsAddrStaysOffTheAPIPort(t *testing.T) {
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.MetricsAddr != "127.0.0.1:9090" {
+		t.Errorf("default METRICS_ADDR = %q, want 127.0.0.1:9090", cfg.Server.MetricsAddr)
+	}
+
+	t.Setenv("METRICS_ADDR", "")
+	if _, err := load(t); err != nil {
+		t.Errorf("empty METRICS_ADDR rejected: %v", err)
+	}
+
+	for _, addr := range []string{":8080", "9090", "localhost:http"} {
+		t.Setenv("METRICS_ADDR", addr)
+		if _, err := load(t); err == nil || !strings.Contains(err.Error(), "METRICS_ADDR") {
+			t.Errorf("METRICS_ADDR=%q error = %v, want it rejected", addr, err)
+		}
+	}
+}
+
+func TestDatabaseDrivers(t *testing.T) {
+	t.Setenv("DB_TYPE", "sqlite")
+	t.Setenv("DB_NAME", "data/app.db")
+	t.Setenv("DB_HOST", "")
+	t.Setenv("DB_SSLMODE", "")
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("Load() error = %v; sqlite needs no host or sslmode", err)
+	}
+	if got := cfg.Database.DSN(); got != "data/app.db?_foreign_keys=on&_busy_timeout=5000" {
+		t.Errorf("sqlite DSN = %q", got)
+	}
+
+	t.Setenv("DB_TYPE", "mysql")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_PORT", "3306")
+	t.Setenv("DB_NAME", "mydb")
+	t.Setenv("DB_TIMEZONE", "Asia/Jakarta")
+	if cfg, err = load(t); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Database.DSN(); !strings.HasPrefix(got, "postgres:@tcp(db:3306)/mydb?") || !strings.Contains(got, "loc=Asia%2FJakarta") {
+		t.Errorf("mysql DSN = %q", got)
+	}
+
+	t.Setenv("DB_TYPE", "oracle")
+	if _, err := load(t); err == nil || !strings.Contains(err.Error(), "DB_TYPE: must be postgres, mysql or sqlite") {
+		t.Fatalf("Load() error = %v, want DB_TYPE rejected", err)
+	}
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	path := writeFile(t, "database:\n  hostname: db.internal\n")
+
+	if _, err := load(t, "-config", path); err == nil || !strings.Contains(err.Error(), "hostname") {
+		t.Fatalf("Load() error = %v, want the unknown key named", err)
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Password = "db-password"
+	cfg.JWT.Secret = "jwt-secret"
+	cfg.Redis.Password = ""
+
+	out := cfg.String()
+	for _, secret := range []string{"db-password", "jwt-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("printed config leaks %q", secret)
+		}
+	}
+	if !strings.Contains(out, "database.password") || !strings.Contains(out, "******") {
+		t.Errorf("printed config should show redacted secrets:\n%s", out)
+	}
+	if !strings.Contains(out, "rbac.role_expiry_notice") || !strings.Contains(out, "24h0m0s") {
+		t.Errorf("printed config should list durations readably:\n%s", out)
+	}
+}
+
+func TestLoadReadsSecretsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "db_password")
+	if err := os.WriteFile(passwordFile, []byte("from-credential\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_PASSWORD_FILE", passwordFile)
+
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Database.Password != "from-credential" {
+		t.Fatalf("db password = %q, want the file content without the newline", cfg.Database.Password)
+	}
+
+	t.Setenv("DB_PASSWORD", "from-env")
+	if _, err := load(t); err == nil || !strings.Contains(err.Error(), "set only one of DB_PASSWORD and DB_PASSWORD_FILE") {
+		t.Fatalf("Load() error = %v, want a conflict between DB_PASSWORD and DB_PASSWORD_FILE", err)
+	}
+}
+
+func TestLoadDecryptsSecretsFile(t *testing.T) {
+	encoded, err := secrets.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := secrets.ParseKey(encoded)
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := secrets.WriteFile(path, key, []byte("DB_PASSWORD: encrypted\nSMTP_PASSWORD: mail\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := load(t, "-secrets", path); err == nil || !strings.Contains(err.Error(), "SECRETS_KEY: is required") {
+		t.Fatalf("Load() without a key error = %v", err)
+	}
+
+	t.Setenv("SECRETS_KEY", encoded)
+	t.Setenv("SMTP_PASSWORD", "from-env")
+	cfg, err := load(t, "-secrets", path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Database.Password != "encrypted" {
+		t.Errorf("db password = %q, want the secrets file value", cfg.Database.Password)
+	}
+	if cfg.SMTP.Password != "from-env" {
+		t.Errorf("smtp password = %q, want the environment to override the secrets file", cfg.SMTP.Password)
+	}
+
+	if err := secrets.WriteFile(path, key, []byte("DB_PASSWROD: typo\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := load(t, "-secrets", path); err == nil || !strings.Contains(err.Error(), "unknown setting DB_PASSWROD") {
+		t.Fatalf("Load() error = %v, want the unknown name reported", err)
+	}
+}
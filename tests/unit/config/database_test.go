@@ -0,0 +1,132 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-backend/config"
+	"golang-backend/entity"
+	"golang-backend/reqctx"
+
+	"gorm.io/gorm"
+)
+
+func TestSQLLogRedactsParameters(t *testing.T) {
+	var out bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	for _, logParams := range []bool{false, true} {
+		out.Reset()
+		db, err := config.OpenDatabase(config.DatabaseConfig{
+			Driver:             "sqlite",
+			Name:               filepath.Join(t.TempDir(), "app.db"),
+			LogLevel:           "warn",
+			SlowQueryThreshold: time.Nanosecond,
+			LogParams:          logParams,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.Exec("CREATE TABLE accounts (password TEXT)")
+		db.Exec("INSERT INTO accounts (password) VALUES (?)", "hunter2")
+
+		logged := out.String()
+		if !strings.Contains(logged, "Slow SQL statement") {
+			t.Fatalf("slow statements were not logged:\n%s", logged)
+		}
+		if strings.Contains(logged, "hunter2") != logParams {
+			t.Errorf("LogParams=%v, but the parameter logged is %v:\n%s", logParams, !logParams, logged)
+		}
+	}
+}
+
+func TestDatabaseStatementTimeout(t *testing.T) {
+	cfg := config.Default().Database
+	if !strings.Contains(cfg.DSN(), "statement_timeout=30000") {
+		t.Errorf("postgres DSN %q does not set statement_timeout", cfg.DSN())
+	}
+
+	cfg.Driver = "mysql"
+	if !strings.Contains(cfg.DSN(), "max_execution_time=30000") {
+		t.Errorf("mysql DSN %q does not set max_execution_time", cfg.DSN())
+	}
+
+	cfg.StatementTimeout = 0
+	if strings.Contains(cfg.DSN(), "max_execution_time") {
+		t.Errorf("DSN %q sets a timeout although DB_STATEMENT_TIMEOUT is 0", cfg.DSN())
+	}
+}
+
+func TestDatabaseMultiStatementsOnlyForMigrations(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.Driver = "mysql"
+	if strings.Contains(cfg.DSN(), "multiStatements") {
+		t.Errorf("application DSN %q allows stacked queries", cfg.DSN())
+	}
+	if !strings.Contains(cfg.MigrationDSN(), "multiStatements=true") {
+		t.Errorf("migration DSN %q does not allow several statements", cfg.MigrationDSN())
+	}
+}
+
+func TestReplicaRouting(t *testing.T) {
+	open := func(name, note string) (*gorm.DB, config.DatabaseConfig) {
+		cfg := config.DatabaseConfig{Driver: "sqlite", Name: filepath.Join(t.TempDir(), name), LogLevel: "silent"}
+		db, err := config.OpenDatabase(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.Exec("CREATE TABLE notes (name TEXT)")
+		db.Exec("INSERT INTO notes (name) VALUES (?)", note)
+		return db, cfg
+	}
+	primary, cfg := open("primary.db", "primary")
+	_, replicaCfg := open("replica.db", "replica")
+
+	cfg.Replicas = []string{replicaCfg.DSN()}
+	cfg.ReplicaHealthInterval = time.Hour
+	cfg.ReadYourWrites = time.Minute
+	stop, err := config.UseReplicas(primary, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	read := func(db *gorm.DB) string {
+		var name string
+		if err := db.Table("notes").Select("name").Scan(&name).Error; err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+	writer := reqctx.WithPrincipal(context.Background(), &entity.User{Base: entity.Base{ID: "writer"}})
+	other := reqctx.WithPrincipal(context.Background(), &entity.User{Base: entity.Base{ID: "other"}})
+
+	if got := read(primary); got != "primary" {
+		t.Errorf("unmarked read served by %s", got)
+	}
+	if got := read(config.ReadFromReplica(primary.WithContext(writer))); got != "replica" {
+		t.Errorf("marked read served by %s", got)
+	}
+
+	primary.WithContext(writer).Exec("UPDATE notes SET name = ?", "primary")
+	if got := read(config.ReadFromReplica(primary.WithContext(writer))); got != "primary" {
+		t.Errorf("read after the user's own write served by %s", got)
+	}
+	if got := read(config.ReadFromReplica(primary.WithContext(other))); got != "replica" {
+		t.Errorf("another user's read served by %s", got)
+	}
+
+	primary.Transaction(func(tx *gorm.DB) error {
+		if got := read(config.ReadFromReplica(tx)); got != "primary" {
+			t.Errorf("read inside a transaction served by %s", got)
+		}
+		return nil
+	})
+}
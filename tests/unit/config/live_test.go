@@ -0,0 +1,68 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"golang-backend/config"
+)
+
+func TestLiveReloadSwapsReloadableSettings(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
+	path := writeFile(t, "rate_limit:\n  ip_rate: 5\nserver:\n  port: 9000\n")
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := config.NewLoader(flags)
+	if err := flags.Parse([]string{"-config", path}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := config.NewLive(cfg, loader)
+	var changes int
+	live.OnChange(func(previous, current *config.Config) {
+		changes++
+		if previous.RateLimit.IPRate != 5 || current.RateLimit.IPRate != 50 {
+			t.Errorf("change from %v to %v", previous.RateLimit.IPRate, current.RateLimit.IPRate)
+		}
+	})
+
+	if err := os.WriteFile(path, []byte("rate_limit:\n  ip_rate: 50\nserver:\n  port: 9001\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result := live.Reload(config.ReloadSignal)
+	if !result.Success {
+		t.Fatalf("Reload() failed: %s", result.Error)
+	}
+	if strings.Join(result.Applied, ",") != "rate_limit.ip_rate" || strings.Join(result.RestartRequired, ",") != "server.port" {
+		t.Errorf("applied = %v, restart required = %v", result.Applied, result.RestartRequired)
+	}
+	if got := live.Get(); got.RateLimit.IPRate != 50 || got.Server.Port != 9000 {
+		t.Errorf("active ip rate = %v, port = %d; want 50 and the startup port 9000", got.RateLimit.IPRate, got.Server.Port)
+	}
+	if cfg.RateLimit.IPRate != 5 {
+		t.Error("reload modified the previous configuration in place")
+	}
+	if status := live.Status(); status.Version != 2 || changes != 1 {
+		t.Errorf("version = %d, listener calls = %d; want 2 and 1", status.Version, changes)
+	}
+
+	if err := os.WriteFile(path, []byte("rate_limit:\n  ip_rate: -1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result = live.Reload(config.ReloadFile)
+	if result.Success || !strings.Contains(result.Error, "RATE_LIMIT_IP_RPS") {
+		t.Fatalf("Reload() of an invalid file = %+v, want a validation error", result)
+	}
+	status := live.Status()
+	if live.Get().RateLimit.IPRate != 50 || status.Version != 2 {
+		t.Errorf("an invalid reload must keep version 2 in effect, got version %d", status.Version)
+	}
+	if status.LastReload == nil || status.LastReload.Trigger != config.ReloadFile || status.LastReload.Success {
+		t.Errorf("last reload = %+v", status.LastReload)
+	}
+}
@@ -0,0 +1,54 @@
+package entity_test
+
+import (
+	"golang-backend/entity"
+	"testing"
+)
+
+func TestGroup_NestedRoles(t *testing.T) {
+	editor := &entity.Role{Base: entity.Base{ID: "r1"}, Name: "editor", Permissions: []*entity.Permission{{Name: "posts:*"}}}
+	viewer := &entity.Role{Base: entity.Base{ID: "r2"}, Name: "viewer", Permissions: []*entity.Permission{{Name: "reports:read"}}}
+
+	company := &entity.Group{Base: entity.Base{ID: "g1"}, OrganizationID: "o1", Roles: []*entity.Role{viewer}}
+	engineering := &entity.Group{Base: entity.Base{ID: "g2"}, OrganizationID: "o1", Parent: company, Roles: []*entity.Role{editor}}
+	backend := &entity.Group{Base: entity.Base{ID: "g3"}, OrganizationID: "o1", Parent: engineering, Roles: []*entity.Role{viewer}}
+
+	if got := len(backend.EffectiveRoles()); got != 2 {
+		t.Errorf("Expected 2 distinct effective roles, got %d", got)
+	}
+	if !backend.DescendsFrom("g1") || company.DescendsFrom("g3") {
+		t.Error("DescendsFrom should follow parent links upwards only")
+	}
+
+	// A corrupt parent link must not loop forever
+	company.Parent = backend
+	if got := len(backend.Lineage()); got != 3 {
+		t.Errorf("Expected lineage of 3 groups, got %d", got)
+	}
+}
+
+func TestUser_GroupPermissions(t *testing.T) {
+	editor := &entity.Role{Base: entity.Base{ID: "r1"}, Name: "editor", Permissions: []*entity.Permission{{Name: "posts:*"}}}
+	team := &entity.Group{Base: entity.Base{ID: "g1"}, OrganizationID: "o1", Roles: []*entity.Role{editor}}
+	user := &entity.User{Groups: []*entity.Group{team}}
+
+	if !user.HasPermission("posts:update") {
+		t.Error("User should get permissions through their group")
+	}
+	if !user.HasImpliedRole("editor") {
+		t.Error("HasImpliedRole should include group roles")
+	}
+	if got := len(user.EffectivePermissions()); got != 1 {
+		t.Errorf("Expected 1 effective permission, got %d", got)
+	}
+
+	user.UserPermissions = []*entity.UserPermission{{Effect: entity.PermissionEffectDeny, Permission: &entity.Permission{Name: "posts:delete"}}}
+	if user.HasPermission("posts:delete") {
+		t.Error("A direct deny should override a group grant")
+	}
+
+	user.ScopeGroups("")
+	if user.HasPermission("posts:update") {
+		t.Error("Group grants should not apply without a tenant")
+	}
+}
@@ -0,0 +1,57 @@
+package entity_test
+
+import (
+	"golang-backend/entity"
+	"testing"
+)
+
+func TestMatchPermission(t *testing.T) {
+	tests := []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		{"users:read", "users:read", true},
+		{"users:read", "users:update", false},
+		{"users:*", "users:delete", true},
+		{"users:*", "roles:read", false},
+		{"*:read", "reports:read", true},
+		{entity.PermissionAll, "anything:goes", true},
+		{"report*:read", "reports:read", true},
+		{"manage_users", "manage_users", true},
+		{"*:*", "manage_users", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granted+"->"+tt.required, func(t *testing.T) {
+			if got := entity.MatchPermission(tt.granted, tt.required); got != tt.want {
+				t.Errorf("MatchPermission(%q, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidPermissionName(t *testing.T) {
+	valid := []string{"users:read", "users:*", "*:*", "audit-logs:read"}
+	invalid := []string{"manage_users", "users:", ":read", "Users:Read", "users:read:own"}
+
+	for _, name := range valid {
+		if !entity.ValidPermissionName(name) {
+			t.Errorf("%q should be valid", name)
+		}
+	}
+	for _, name := range invalid {
+		if entity.ValidPermissionName(name) {
+			t.Errorf("%q should be invalid", name)
+		}
+	}
+}
+
+func TestUser_HasPermissionWildcard(t *testing.T) {
+	admin := &entity.Role{Name: "admin", Permissions: []*entity.Permission{{Name: entity.PermissionAll}}}
+	user := &entity.User{Roles: []*entity.Role{admin}}
+
+	if !user.HasPermission("users:delete") {
+		t.Error("Super-admin wildcard should grant users:delete")
+	}
+}
@@ -0,0 +1,49 @@
+package entity_test
+
+import (
+	"golang-backend/entity"
+	"testing"
+)
+
+func TestRole_Hierarchy(t *testing.T) {
+	view := &entity.Permission{Name: "view_reports"}
+	edit := &entity.Permission{Name: "edit_reports"}
+
+	user := &entity.Role{Name: "user", Permissions: []*entity.Permission{view}}
+	manager := &entity.Role{Name: "manager", Permissions: []*entity.Permission{edit}, Parents: []*entity.Role{user}}
+	director := &entity.Role{Name: "director", Parents: []*entity.Role{manager, user}}
+
+	if !director.GrantsPermission("view_reports") {
+		t.Error("Director should inherit view_reports through manager and user")
+	}
+
+	if director.HasPermission("view_reports") {
+		t.Error("HasPermission should only consider directly assigned permissions")
+	}
+
+	if got := len(director.EffectivePermissions()); got != 2 {
+		t.Errorf("Expected 2 effective permissions, got %d", got)
+	}
+
+	if got := len(director.Ancestors()); got != 2 {
+		t.Errorf("Expected 2 distinct ancestors, got %d", got)
+	}
+
+	if !manager.Implies("user") || user.Implies("manager") {
+		t.Error("Implication should only follow parent edges")
+	}
+}
+
+func TestRole_CyclicGraphTerminates(t *testing.T) {
+	a := &entity.Role{Name: "a"}
+	b := &entity.Role{Name: "b", Parents: []*entity.Role{a}}
+	a.Parents = []*entity.Role{b}
+
+	if a.GrantsPermission("anything") {
+		t.Error("No permission should be granted in an empty cycle")
+	}
+
+	if got := len(a.Ancestors()); got != 1 {
+		t.Errorf("Expected 1 ancestor, got %d", got)
+	}
+}
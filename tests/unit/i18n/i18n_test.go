@@ -0,0 +1,98 @@
+package i18n_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"golang-backend/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                             i18n.English,
+		"id":                           i18n.Indonesian,
+		"id-ID,id;q=0.9,en;q=0.8":      i18n.Indonesian,
+		"fr-FR,en;q=0.5,id;q=0.7":      i18n.Indonesian,
+		"en-US,en;q=0.9":               i18n.English,
+		"de-DE":                        i18n.English,
+		"id;q=0,en;q=0.1":              i18n.English,
+		"EN-gb;q=0.4, id-ID;q=invalid": i18n.English,
+	}
+
+	for header, want := range cases {
+		if got := i18n.Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := i18n.T(i18n.Indonesian, "User not found"); got != "User tidak ditemukan" {
+		t.Fatalf("T(id) = %q", got)
+	}
+	if got := i18n.T(i18n.English, "User not found"); got != "User not found" {
+		t.Fatalf("T(en) = %q", got)
+	}
+	if got := i18n.T(i18n.Indonesian, "Not in the catalog"); got != "Not in the catalog" {
+		t.Fatalf("missing translations should fall back to English, got %q", got)
+	}
+	if got := i18n.T(i18n.Indonesian, "Your verification code is: <b>%s</b>", "123456"); got != "Kode verifikasi Anda adalah: <b>123456</b>" {
+		t.Fatalf("T(id) with args = %q", got)
+	}
+}
+
+func TestDefaultLocale(t *testing.T) {
+	t.Cleanup(func() { _ = i18n.SetDefault(i18n.English) })
+
+	if err := i18n.SetDefault("fr"); err == nil {
+		t.Fatal("expected an unsupported default locale to fail")
+	}
+	if err := i18n.SetDefault(i18n.Indonesian); err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+	if got := i18n.T("", "Unauthorized"); got != "Tidak terautentikasi" {
+		t.Fatalf("empty locale should use the default, got %q", got)
+	}
+	if got := i18n.Negotiate("de-DE"); got != i18n.Indonesian {
+		t.Fatalf("Negotiate() = %q, want the default", got)
+	}
+}
+
+func TestLocalePrefersTheStoredLocale(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Accept-Language", "en-US")
+
+	if got := i18n.Locale(c); got != i18n.English {
+		t.Fatalf("Locale() = %q, want the negotiated locale", got)
+	}
+	i18n.SetLocale(c, i18n.Indonesian)
+	if got := i18n.Locale(c); got != i18n.Indonesian {
+		t.Fatalf("Locale() = %q, want the user's locale", got)
+	}
+}
+
+// Translations must keep the fmt verbs and placeholders of the English text.
+func TestCatalogsKeepPlaceholders(t *testing.T) {
+	placeholders := regexp.MustCompile(`%[a-z]|\{param\}`)
+	for _, message := range []string{
+		"Your verification code is: <b>%s</b>",
+		"Your reset password code is: <b>%s</b>",
+		"Your <b>%s</b> role expires on %s.",
+		"You have been invited to join <b>%s</b>. Sign in to accept the invitation.",
+		"must be at least {param} characters",
+		"must be one of: {param}",
+	} {
+		for _, locale := range i18n.Locales() {
+			want := placeholders.FindAllString(message, -1)
+			got := placeholders.FindAllString(i18n.T(locale, message), -1)
+			if len(got) != len(want) {
+				t.Errorf("%s translation of %q has placeholders %v, want %v", locale, message, got, want)
+			}
+		}
+	}
+}
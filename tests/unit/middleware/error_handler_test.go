@@ -0,0 +1,116 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-backend/apperror"
+	"golang-backend/dto"
+	"golang-backend/middleware"
+	"golang-backend/utils"
+	"golang-backend/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func errorApp() *gin.Engine {
+	app := gin.New()
+	app.Use(middleware.ErrorHandlerMiddleware())
+	app.GET("/missing", func(c *gin.Context) {
+		c.Error(apperror.NotFound.New("User not found"))
+	})
+	app.GET("/database", func(c *gin.Context) {
+		c.Error(errors.New("pq: password authentication failed for user \"app\""))
+	})
+	app.GET("/panic", func(c *gin.Context) {
+		panic("nil map")
+	})
+	app.GET("/ok", func(c *gin.Context) {
+		utils.SuccessResponse(c, "OK", nil)
+	})
+	return app
+}
+
+func TestErrorHandlerMiddleware(t *testing.T) {
+	cases := []struct {
+		path    string
+		status  int
+		code    string
+		message string
+	}{
+		{"/missing", http.StatusNotFound, "NOT_FOUND", "User not found"},
+		{"/database", http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error"},
+		{"/panic", http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error"},
+		{"/ok", http.StatusOK, "", "OK"},
+	}
+
+	app := errorApp()
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.status)
+			}
+			var body utils.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not the standard envelope: %v", err)
+			}
+			if body.Code != tc.code || body.Message != tc.message {
+				t.Fatalf("body = %+v, want code %q message %q", body, tc.code, tc.message)
+			}
+			if strings.Contains(rec.Body.String(), "password authentication") {
+				t.Fatal("internal cause leaked to the client")
+			}
+		})
+	}
+}
+
+type noEmails struct{}
+
+func (noEmails) EmailExists(context.Context, string) (bool, error) { return false, nil }
+
+func TestErrorHandlerMiddlewareFieldErrors(t *testing.T) {
+	if err := validation.Register(noEmails{}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	app := gin.New()
+	app.Use(middleware.ErrorHandlerMiddleware())
+	app.POST("/register", func(c *gin.Context) {
+		var input dto.UserRegisterRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(apperror.Bind(err))
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"name":"Budi","email":"budi","password":"Rahasia123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	var body struct {
+		Message string              `json:"message"`
+		Code    string              `json:"code"`
+		Errors  map[string][]string `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if body.Message != "Validasi gagal" || body.Code != "VALIDATION_FAILED" {
+		t.Fatalf("body = %+v", body)
+	}
+	if got := body.Errors["email"]; len(got) != 1 || got[0] != "harus berupa email yang valid" {
+		t.Fatalf("errors = %v, want the email field in Indonesian", body.Errors)
+	}
+}
@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-backend/config"
+	"golang-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSMiddlewareFollowsLiveConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	live := config.NewLive(cfg, nil)
+
+	app := gin.New()
+	app.Use(middleware.CORSMiddleware(live))
+	app.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	allowed := func(origin string) string {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	if got := allowed("https://app.example.com"); got != "https://app.example.com" {
+		t.Errorf("allowed origin echoed as %q", got)
+	}
+	if got := allowed("https://evil.example.com"); got != "" {
+		t.Errorf("other origin got Access-Control-Allow-Origin %q", got)
+	}
+}
+
+func TestCORSMiddlewareWildcardWithoutCredentials(t *testing.T) {
+	cfg := config.Default()
+	cfg.CORS.AllowedOrigins = []string{"*", "https://app.example.com"}
+
+	app := gin.New()
+	app.Use(middleware.CORSMiddleware(config.NewLive(cfg, nil)))
+	app.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	headers := func(origin string) http.Header {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec.Header()
+	}
+
+	listed := headers("https://app.example.com")
+	if listed.Get("Access-Control-Allow-Origin") != "https://app.example.com" || listed.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("listed origin got %v, want it echoed with credentials", listed)
+	}
+	other := headers("https://evil.example.com")
+	if other.Get("Access-Control-Allow-Origin") != "*" || other.Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("origin let in by * got %v, want * without credentials", other)
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	cfg := config.Default()
+	cfg.RateLimit = config.RateLimitConfig{IPRate: 1, IPBurst: 2, UserRate: 1, UserBurst: 2}
+
+	app := gin.New()
+	app.Use(middleware.RateLimiterMiddleware(config.NewLive(cfg, nil)))
+	app.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		codes = append(codes, rec.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("status codes = %v, want the third request limited", codes)
+	}
+}
@@ -0,0 +1,167 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-backend/dto"
+	"golang-backend/entity"
+	"golang-backend/middleware"
+	"golang-backend/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	middleware.RegisterUserPolicies()
+}
+
+func contextFor(user *entity.User) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request = ctx.Request.WithContext(reqctx.WithPrincipal(ctx.Request.Context(), user))
+	return ctx
+}
+
+func TestAuthorizeResource_OwnRecord(t *testing.T) {
+	user := &entity.User{Base: entity.Base{ID: "u1"}}
+	other := &entity.User{Base: entity.Base{ID: "u2"}}
+	ctx := contextFor(user)
+
+	if err := middleware.AuthorizeReadResource(ctx, "users", user); err != nil {
+		t.Errorf("User should be able to view their own record: %v", err)
+	}
+	if err := middleware.AuthorizeReadResource(ctx, "users", other); err == nil {
+		t.Error("User should not be able to view another user's record")
+	}
+	if err := middleware.AuthorizeRead(ctx, "users"); err == nil {
+		t.Error("Own-record policy should not grant the whole users collection")
+	}
+}
+
+func TestAuthorizeResource_ManagerDepartment(t *testing.T) {
+	managerRole := &entity.Role{Name: entity.RoleManager}
+	manager := &entity.User{
+		Base:       entity.Base{ID: "m1"},
+		Department: "finance",
+		Roles:      []*entity.Role{managerRole},
+	}
+	colleague := &entity.User{Base: entity.Base{ID: "u1"}, Department: "finance"}
+	outsider := &entity.User{Base: entity.Base{ID: "u2"}, Department: "sales"}
+	ctx := contextFor(manager)
+
+	if err := middleware.AuthorizeEditResource(ctx, "users", &middleware.UserUpdate{User: colleague}); err != nil {
+		t.Errorf("Manager should be able to edit users in their department: %v", err)
+	}
+	if err := middleware.AuthorizeEditResource(ctx, "users", &middleware.UserUpdate{User: outsider}); err == nil {
+		t.Error("Manager should not be able to edit users in another department")
+	}
+
+	staff := &entity.User{Base: entity.Base{ID: "u3"}, Department: "finance"}
+	if err := middleware.AuthorizeEditResource(contextFor(staff), "users", &middleware.UserUpdate{User: colleague}); err == nil {
+		t.Error("Non-manager should not be able to edit colleagues")
+	}
+
+	director := &entity.User{
+		Base:       entity.Base{ID: "d1"},
+		Department: "finance",
+		Roles:      []*entity.Role{{Name: "director", Parents: []*entity.Role{managerRole}}},
+	}
+	admin := &entity.User{Base: entity.Base{ID: "a1"}, Department: "finance", Roles: []*entity.Role{{Name: entity.RoleAdmin}}}
+	peer := &entity.User{Base: entity.Base{ID: "m2"}, Department: "finance", Roles: []*entity.Role{managerRole}}
+	for _, target := range []*entity.User{director, admin} {
+		if err := middleware.AuthorizeEditResource(ctx, "users", &middleware.UserUpdate{User: target}); err == nil {
+			t.Errorf("Manager should not be able to edit %s, who ranks above manager", target.ID)
+		}
+	}
+	if err := middleware.AuthorizeEditResource(ctx, "users", &middleware.UserUpdate{User: peer}); err != nil {
+		t.Errorf("Manager should be able to edit another manager of their department: %v", err)
+	}
+}
+
+func TestAuthorizeResource_ManagerDepartmentChange(t *testing.T) {
+	manager := &entity.User{
+		Base:       entity.Base{ID: "m1"},
+		Department: "finance",
+		Roles:      []*entity.Role{{Name: entity.RoleManager}},
+	}
+	colleague := &entity.User{Base: entity.Base{ID: "u1"}, Department: "finance"}
+	ctx := contextFor(manager)
+	sales, finance := "sales", "finance"
+
+	if err := middleware.AuthorizeEditResource(ctx, "users", &middleware.UserUpdate{User: manager, Changes: dto.UpdateUserRequest{Department: &sales}}); err == nil {
+		t.Error("Manager should not be able to move themselves into another department")
+	}
+	if err := middleware.AuthorizeEditResource(ctx, "users", &middleware.UserUpdate{User: colleague, Changes: dto.UpdateUserRequest{Department: &sales}}); err == nil {
+		t.Error("Manager should not be able to move a colleague out of the department")
+	}
+	if err := middleware.AuthorizeEditResource(ctx, "users", &middleware.UserUpdate{User: colleague, Changes: dto.UpdateUserRequest{Department: &finance}}); err != nil {
+		t.Errorf("Resending the current department should be allowed: %v", err)
+	}
+}
+
+func TestAuthorizeDepartmentChange_NeedsGlobalGrant(t *testing.T) {
+	update := &entity.Permission{Name: "users:update"}
+	owner := &entity.User{
+		Base:        entity.Base{ID: "o1"},
+		TenantRoles: []*entity.Role{{Name: entity.RoleOrganizationOwner, Permissions: []*entity.Permission{update}}},
+	}
+	member := &entity.User{Base: entity.Base{ID: "u1"}, Department: "finance"}
+	sales, finance := "sales", "finance"
+	name := "Renamed"
+
+	if err := middleware.AuthorizeDepartmentChange(contextFor(owner), &middleware.UserUpdate{User: member, Changes: dto.UpdateUserRequest{Department: &sales}}); err == nil {
+		t.Error("A per-org users:update should not move a member to another department")
+	}
+	for _, changes := range []dto.UpdateUserRequest{{Name: &name}, {Department: &finance}} {
+		if err := middleware.AuthorizeDepartmentChange(contextFor(owner), &middleware.UserUpdate{User: member, Changes: changes}); err != nil {
+			t.Errorf("Changes leaving the department alone should pass: %v", err)
+		}
+	}
+
+	admin := &entity.User{Base: entity.Base{ID: "a1"}, Roles: []*entity.Role{{Name: entity.RoleAdmin, Permissions: []*entity.Permission{update}}}}
+	if err := middleware.AuthorizeDepartmentChange(contextFor(admin), &middleware.UserUpdate{User: member, Changes: dto.UpdateUserRequest{Department: &sales}}); err != nil {
+		t.Errorf("A global users:update should move members: %v", err)
+	}
+}
+
+func TestAuthorizeResource_PermissionAndDeny(t *testing.T) {
+	update := &entity.Permission{Name: "users:update"}
+	admin := &entity.User{
+		Base:  entity.Base{ID: "a1"},
+		Roles: []*entity.Role{{Name: entity.RoleAdmin, Permissions: []*entity.Permission{{Name: entity.PermissionAll}}}},
+	}
+	target := &entity.User{Base: entity.Base{ID: "u1"}, Department: "sales"}
+
+	if err := middleware.AuthorizeEditResource(contextFor(admin), "users", &middleware.UserUpdate{User: target}); err != nil {
+		t.Errorf("Permission holder should not need a policy: %v", err)
+	}
+
+	manager := &entity.User{
+		Base:            entity.Base{ID: "m1"},
+		Department:      "sales",
+		Roles:           []*entity.Role{{Name: entity.RoleManager}},
+		UserPermissions: []*entity.UserPermission{{Effect: entity.PermissionEffectDeny, Permission: update}},
+	}
+	if err := middleware.AuthorizeEditResource(contextFor(manager), "users", &middleware.UserUpdate{User: target}); err == nil {
+		t.Error("Explicit deny should override a matching policy")
+	}
+}
+
+func TestCan_EvaluatesAnyPrincipal(t *testing.T) {
+	reader := &entity.User{
+		Base:  entity.Base{ID: "r1"},
+		Roles: []*entity.Role{{Name: "auditor", Permissions: []*entity.Permission{{Name: "users:*"}}}},
+	}
+	// The context belongs to someone else; Can must judge the given user
+	ctx := contextFor(&entity.User{Base: entity.Base{ID: "u1"}})
+
+	if !middleware.Can(ctx, reader, "read", "users") {
+		t.Error("users:* should allow users:read")
+	}
+	if middleware.Can(ctx, reader, "read", "reports") {
+		t.Error("users:* should not allow reports:read")
+	}
+}
@@ -0,0 +1,47 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-backend/entity"
+	"golang-backend/middleware"
+	"golang-backend/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRouteRegistry_RecordsAndEnforcesPermissions(t *testing.T) {
+	app := gin.New()
+	app.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(reqctx.WithPrincipal(c.Request.Context(), &entity.User{
+			Roles: []*entity.Role{{Name: "viewer", Permissions: []*entity.Permission{{Name: "roles:read"}}}},
+		}))
+	})
+
+	registry := middleware.NewRouteRegistry()
+	admin := app.Group("/api").Group("/admin")
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	registry.Handle(admin, http.MethodGet, "/roles", "roles:read", ok)
+	registry.Handle(admin, http.MethodPost, "/roles", "roles:create", ok)
+	registry.HandlePolicy(admin, http.MethodGet, "/roles/:id", "roles:read", ok)
+
+	route, found := registry.Lookup(http.MethodGet, "/api/admin/roles/:id")
+	if !found || route.Permission != "roles:read" || !route.Policy {
+		t.Errorf("Expected policy route roles:read, got %+v (found %v)", route, found)
+	}
+
+	permissions := registry.Permissions()
+	if len(permissions) != 2 || permissions[0] != "roles:create" || permissions[1] != "roles:read" {
+		t.Errorf("Expected distinct sorted permissions, got %v", permissions)
+	}
+
+	for method, want := range map[string]int{http.MethodGet: http.StatusOK, http.MethodPost: http.StatusForbidden} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(method, "/api/admin/roles", nil))
+		if w.Code != want {
+			t.Errorf("%s /api/admin/roles: expected %d, got %d", method, want, w.Code)
+		}
+	}
+}
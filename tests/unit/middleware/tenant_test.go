@@ -0,0 +1,125 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-backend/entity"
+	"golang-backend/middleware"
+	"golang-backend/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func tenantApp(user *entity.User, seen *string) *gin.Engine {
+	app := gin.New()
+	app.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(reqctx.WithPrincipal(c.Request.Context(), user))
+	}, middleware.TenantMiddleware("api.example.com"))
+	handler := func(c *gin.Context) {
+		*seen = middleware.TenantID(c)
+		c.Status(http.StatusOK)
+	}
+	app.GET("/members", middleware.OrganizationScope(), middleware.PermissionAuthMiddleware("members:read"), handler)
+	// Not scoped to the organization, so per-org roles must not count
+	app.GET("/global", middleware.PermissionAuthMiddleware("members:read"), handler)
+	return app
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	acme := "org-acme"
+	user := &entity.User{
+		Base:                 entity.Base{ID: "u1"},
+		ActiveOrganizationID: &acme,
+		Memberships: []*entity.Membership{
+			{
+				OrganizationID: acme,
+				Status:         entity.MembershipActive,
+				Organization:   &entity.Organization{Slug: "acme"},
+				Roles:          []*entity.Role{{Name: "member", Permissions: []*entity.Permission{{Name: "members:read"}}}},
+			},
+			{
+				OrganizationID: "org-globex",
+				Status:         entity.MembershipInvited,
+				Organization:   &entity.Organization{Slug: "globex"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name   string
+		path   string
+		host   string
+		header string
+		status int
+		tenant string
+	}{
+		{"header slug", "/members", "localhost", "acme", http.StatusOK, acme},
+		{"subdomain", "/members", "acme.api.example.com", "", http.StatusOK, acme},
+		{"active organization fallback", "/members", "localhost", "", http.StatusOK, acme},
+		{"pending invitation", "/members", "localhost", "globex", http.StatusForbidden, ""},
+		{"not a member", "/members", "initech.api.example.com", "", http.StatusForbidden, ""},
+		{"per-org role outside the organization scope", "/global", "localhost", "acme", http.StatusForbidden, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Every request gets a fresh principal, as AuthMiddleware provides
+			principal := *user
+			principal.Roles = nil
+			var seen string
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Host = tc.host
+			if tc.header != "" {
+				req.Header.Set(middleware.OrganizationHeader, tc.header)
+			}
+			w := httptest.NewRecorder()
+			tenantApp(&principal, &seen).ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Errorf("Expected status %d, got %d", tc.status, w.Code)
+			}
+			if principal.HasRole("member") {
+				t.Error("Expected per-org roles to stay out of the global roles")
+			}
+			if seen != tc.tenant {
+				t.Errorf("Expected tenant %q, got %q", tc.tenant, seen)
+			}
+		})
+	}
+}
+
+func TestScopePrincipal(t *testing.T) {
+	acme := "org-acme"
+	principal := func() *entity.User {
+		return &entity.User{
+			Base: entity.Base{ID: "u1"},
+			Memberships: []*entity.Membership{{
+				OrganizationID: acme,
+				Status:         entity.MembershipActive,
+				Organization:   &entity.Organization{Slug: "acme"},
+				Roles:          []*entity.Role{{Name: "member", Permissions: []*entity.Permission{{Name: "members:read"}}}},
+			}},
+			Groups: []*entity.Group{{
+				OrganizationID: acme,
+				Roles:          []*entity.Role{{Name: "reporter", Permissions: []*entity.Permission{{Name: "reports:read"}}}},
+			}},
+		}
+	}
+
+	for _, ref := range []string{"", "initech"} {
+		user := principal()
+		middleware.ScopePrincipal(user, ref)
+		if user.HasPermission("reports:read") || user.HasPermission("members:read") {
+			t.Errorf("organization %q: expected group and per-org grants to stay out", ref)
+		}
+	}
+
+	user := principal()
+	middleware.ScopePrincipal(user, "acme")
+	if !user.HasPermission("reports:read") || !user.HasPermission("members:read") {
+		t.Error("Expected group and per-org grants inside the organization")
+	}
+}
@@ -0,0 +1,39 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-backend/config"
+	"golang-backend/middleware"
+	"golang-backend/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.RequestTimeout = 10 * time.Millisecond
+
+	var requestID string
+	app := gin.New()
+	app.Use(middleware.RequestIDMiddleware(), middleware.TimeoutMiddleware(config.NewLive(cfg, nil)), middleware.ErrorHandlerMiddleware())
+	app.GET("/slow", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		requestID = reqctx.RequestID(ctx)
+		<-ctx.Done()
+		c.Error(ctx.Err())
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if requestID == "" || requestID != rec.Header().Get(middleware.RequestIDHeader) {
+		t.Errorf("request context carries ID %q, header has %q", requestID, rec.Header().Get(middleware.RequestIDHeader))
+	}
+}
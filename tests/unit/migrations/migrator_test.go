@@ -0,0 +1,276 @@
+package migrations_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang-backend/config"
+	"golang-backend/migrations"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func exec(sql string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error { return tx.Exec(sql).Error }
+}
+
+func testMigrations() []migrations.Migration {
+	return []migrations.Migration{
+		{Version: 1, Name: "products", Up: exec("CREATE TABLE products (id TEXT PRIMARY KEY)"), Down: exec("DROP TABLE products")},
+		{Version: 2, Name: "products_name", Up: exec("ALTER TABLE products ADD COLUMN name TEXT"), Down: exec("ALTER TABLE products DROP COLUMN name")},
+		{Version: 3, Name: "orders", Up: exec("CREATE TABLE orders (id TEXT PRIMARY KEY)"), Down: exec("DROP TABLE orders")},
+	}
+}
+
+func applied(t *testing.T, m *migrations.Migrator) []int {
+	t.Helper()
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigratorUpDownRedo(t *testing.T) {
+	db := openDB(t)
+	m := migrations.New(db, testMigrations())
+
+	if done, err := m.Up(2); err != nil || len(done) != 2 {
+		t.Fatalf("Up(2) = %d migrations, %v", len(done), err)
+	}
+	if got := applied(t, m); len(got) != 2 {
+		t.Fatalf("applied = %v, want 1 and 2", got)
+	}
+
+	if done, err := m.Up(0); err != nil || len(done) != 1 || done[0].Version != 3 {
+		t.Fatalf("Up(0) = %v, %v; want only migration 3", done, err)
+	}
+	if !db.Migrator().HasTable("orders") || !db.Migrator().HasColumn("products", "name") {
+		t.Fatal("schema changes were not applied")
+	}
+
+	if done, err := m.Down(2); err != nil || len(done) != 2 || done[0].Version != 3 || done[1].Version != 2 {
+		t.Fatalf("Down(2) = %v, %v; want 3 then 2", done, err)
+	}
+	if db.Migrator().HasTable("orders") || db.Migrator().HasColumn("products", "name") {
+		t.Fatal("schema changes were not reverted")
+	}
+
+	if err := m.Redo(); err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	if got := applied(t, m); len(got) != 1 || got[0] != 1 || !db.Migrator().HasTable("products") {
+		t.Fatalf("after Redo applied = %v, want 1", got)
+	}
+}
+
+func TestMigratorRollsBackFailedMigration(t *testing.T) {
+	db := openDB(t)
+	list := testMigrations()
+	list[1].Up = func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE products ADD COLUMN name TEXT").Error; err != nil {
+			return err
+		}
+		return errors.New("boom")
+	}
+	m := migrations.New(db, list)
+
+	done, err := m.Up(0)
+	if err == nil || !strings.Contains(err.Error(), "0002_products_name") {
+		t.Fatalf("Up() error = %v, want the failing migration named", err)
+	}
+	if len(done) != 1 {
+		t.Fatalf("Up() applied %d migrations before failing, want 1", len(done))
+	}
+	if db.Migrator().HasColumn("products", "name") {
+		t.Fatal("the failed migration's change was kept")
+	}
+	if got := applied(t, m); len(got) != 1 {
+		t.Fatalf("applied = %v, want only 1", got)
+	}
+}
+
+func TestMigratorDryRun(t *testing.T) {
+	db := openDB(t)
+	var out strings.Builder
+	m := migrations.New(db, testMigrations())
+	m.DryRun = true
+	m.Out = &out
+
+	if _, err := m.Up(0); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	for _, want := range []string{"-- up 0001_products", "CREATE TABLE products (id TEXT PRIMARY KEY);", "ALTER TABLE products ADD COLUMN name TEXT;"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if db.Migrator().HasTable("products") || db.Migrator().HasTable("schema_migrations") {
+		t.Fatal("dry run changed the database")
+	}
+}
+
+func TestMigratorDryRunRefusedOnMySQL(t *testing.T) {
+	// Never connects: the dry run must be refused before any statement runs
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:1)/app", SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Discard, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	m := migrations.New(db, testMigrations())
+	m.DryRun = true
+	m.Out = &out
+
+	if _, err := m.Up(0); err == nil || !strings.Contains(err.Error(), "not supported on MySQL") {
+		t.Errorf("Up() error = %v, want the MySQL dry run refusal", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("refused dry run printed SQL:\n%s", out.String())
+	}
+}
+
+func TestMigratorStatusReportsMissingMigrations(t *testing.T) {
+	db := openDB(t)
+	if _, err := migrations.New(db, testMigrations()).Up(0); err != nil {
+		t.Fatal(err)
+	}
+
+	m := migrations.New(db, testMigrations()[:2])
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 || !statuses[2].Missing || statuses[2].Name != "orders" {
+		t.Fatalf("statuses = %+v, want 0003_orders reported missing", statuses)
+	}
+	if _, err := m.Down(1); err == nil || !strings.Contains(err.Error(), "not in this build") {
+		t.Fatalf("Down() error = %v, want a missing migration error", err)
+	}
+}
+
+func TestRegisteredMigrations(t *testing.T) {
+	all := migrations.Registered()
+	if len(all) == 0 || all[0].ID() != "0001_initial_schema" {
+		t.Fatalf("first migration = %v, want 0001_initial_schema", all)
+	}
+	for i, m := range all {
+		if m.Version != i+1 {
+			t.Errorf("migration %s: versions should be numbered 1, 2, 3, ...", m.ID())
+		}
+		if m.Up == nil || m.Down == nil {
+			t.Errorf("migration %s needs both up and down", m.ID())
+		}
+	}
+}
+
+func TestBuiltInMigrationsRunOnSQLite(t *testing.T) {
+	db, err := config.OpenDatabase(config.DatabaseConfig{Driver: "sqlite", Name: filepath.Join(t.TempDir(), "app.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := migrations.NewMigrator(db)
+	all := migrations.Registered()
+
+	if done, err := m.Up(0); err != nil || len(done) != len(all) {
+		t.Fatalf("Up() = %d migrations, %v; want all %d", len(done), err, len(all))
+	}
+	for _, table := range []string{"users", "roles", "memberships", "audit_logs"} {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s was not created", table)
+		}
+	}
+
+	if done, err := m.Down(len(all)); err != nil || len(done) != len(all) {
+		t.Fatalf("Down() = %d migrations, %v; want all %d", len(done), err, len(all))
+	}
+	if db.Migrator().HasTable("users") {
+		t.Fatal("tables remain after reverting every migration")
+	}
+}
+
+func TestInitialSchemaRenamesLegacyPermissions(t *testing.T) {
+	db, err := config.OpenDatabase(config.DatabaseConfig{Driver: "sqlite", Name: filepath.Join(t.TempDir(), "app.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A database from before versioned migrations, with the old verb_resource names
+	for _, statement := range []string{
+		"CREATE TABLE roles (id char(26) PRIMARY KEY, created_at datetime, updated_at datetime, deleted_at datetime, name varchar(100) NOT NULL UNIQUE)",
+		"CREATE TABLE permissions (id char(26) PRIMARY KEY, created_at datetime, updated_at datetime, deleted_at datetime, name varchar(100) NOT NULL UNIQUE)",
+		"CREATE TABLE role_permissions (role_id char(26), permission_id char(26), PRIMARY KEY (role_id, permission_id))",
+		"INSERT INTO roles (id, name) VALUES ('r1', 'editor'), ('r2', 'admin')",
+		"INSERT INTO permissions (id, name) VALUES ('p1', 'manage_users'), ('p2', 'users:*'), ('p3', 'view_reports')",
+		"INSERT INTO role_permissions VALUES ('r1', 'p1'), ('r2', 'p1'), ('r2', 'p2'), ('r1', 'p3')",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := migrations.NewMigrator(db).Up(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	if err := db.Table("permissions").Order("name").Pluck("name", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, " ") != "reports:read users:*" {
+		t.Errorf("permissions = %v, want reports:read and users:* with manage_users merged", names)
+	}
+	var grants int64
+	if err := db.Table("role_permissions").Where("permission_id = ?", "p2").Count(&grants).Error; err != nil {
+		t.Fatal(err)
+	}
+	if grants != 2 {
+		t.Errorf("users:* is held by %d roles, want both holders of manage_users", grants)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sql"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	next := len(migrations.Registered()) + 1
+
+	paths, err := migrations.Create(dir, "add_products", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "sql", migrations.Migration{Version: next, Name: "add_products"}.ID()+".up.sql")
+	if len(paths) != 2 || paths[0] != want {
+		t.Fatalf("Create() = %v, want %s and its down file", paths, want)
+	}
+
+	paths, err = migrations.Create(dir, "backfill_products", true)
+	if err != nil || len(paths) != 1 || !strings.HasSuffix(paths[0], migrations.Migration{Version: next + 1, Name: "backfill_products"}.ID()+".go") {
+		t.Fatalf("Create(go) = %v, %v", paths, err)
+	}
+
+	if _, err := migrations.Create(dir, "Bad Name", false); err == nil {
+		t.Fatal("Create() accepted a name that is not lower_snake_case")
+	}
+}
@@ -0,0 +1,208 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"golang-backend/apperror"
+	"golang-backend/config"
+	"golang-backend/entity"
+	"golang-backend/migrations"
+	"golang-backend/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openDB returns a migrated SQLite database, so repositories run the same
+// SQL as on a server configured with DB_TYPE=sqlite.
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := config.OpenDatabase(config.DatabaseConfig{Driver: "sqlite", Name: filepath.Join(t.TempDir(), "app.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrations.NewMigrator(db).Up(0); err != nil {
+		t.Fatal(err)
+	}
+	return db.Session(&gorm.Session{Logger: logger.Discard})
+}
+
+func searchUsers(t *testing.T, repo repository.UserRepository, search string) []string {
+	t.Helper()
+	result, err := repo.Paginate(context.Background(), map[string]interface{}{"search": search, "sort_by": "name", "sort_order": "asc"}, 1, 10)
+	if err != nil {
+		t.Fatalf("Paginate(%q) error = %v", search, err)
+	}
+	var names []string
+	for _, user := range result.Items.([]entity.User) {
+		names = append(names, user.Name)
+	}
+	return names
+}
+
+func TestUserSearch(t *testing.T) {
+	db := openDB(t)
+	repo := repository.NewUserRepository(db)
+	for _, user := range []*entity.User{
+		{Name: "Budi Santoso", Email: "budi@example.com", Password: "x"},
+		{Name: "Siti Rahayu", Email: "siti@example.com", Password: "x"},
+		{Name: "Budiman", Email: "man@example.org", Password: "x"},
+	} {
+		if err := repo.Create(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		// Short terms use LIKE, ignoring case
+		{"bu", []string{"Budi Santoso", "Budiman"}},
+		// Longer terms match word prefixes, through FTS5 when compiled in
+		{"budi", []string{"Budi Santoso", "Budiman"}},
+		{"budi sant", []string{"Budi Santoso"}},
+		{"RAHAYU", []string{"Siti Rahayu"}},
+		{"nobody", nil},
+	}
+	for _, tt := range tests {
+		if got := searchUsers(t, repo, tt.search); !slices.Equal(got, tt.want) {
+			t.Errorf("search %q = %v, want %v", tt.search, got, tt.want)
+		}
+	}
+
+	// Renames reach the search index
+	user, _ := repo.FindByEmail(context.Background(), "siti@example.com")
+	user.Name = "Siti Nurhaliza"
+	if err := repo.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchUsers(t, repo, "nurhaliza"); !slices.Equal(got, []string{"Siti Nurhaliza"}) {
+		t.Errorf("search after rename = %v", got)
+	}
+}
+
+func TestRoleSearchIgnoresCase(t *testing.T) {
+	db := openDB(t)
+	repo := repository.NewRoleRepository(db)
+	for _, name := range []string{"Admin", "Editor", "Super Admin"} {
+		if err := db.Create(&entity.Role{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := repo.PaginateRoles(context.Background(), map[string]interface{}{"search": "admin"}, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roles := result.Items.([]entity.Role); len(roles) != 2 {
+		t.Fatalf("search admin = %d roles, want 2", len(roles))
+	}
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	db := openDB(t)
+	repo := repository.NewAuditLogRepository(db)
+
+	first := &entity.AuditLog{Action: "user.create", TargetType: "user", TargetID: "1"}
+	second := &entity.AuditLog{Action: "user.update", TargetType: "user", TargetID: "1"}
+	for _, log := range []*entity.AuditLog{first, second} {
+		if err := repo.Append(context.Background(), log); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if second.Sequence != 2 || second.PrevHash != first.Hash {
+		t.Fatalf("second record = sequence %d prev %q, want 2 chained to %q", second.Sequence, second.PrevHash, first.Hash)
+	}
+
+	logs, err := repo.FindAfter(context.Background(), 0, 10)
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("FindAfter() = %d records, %v", len(logs), err)
+	}
+	for _, log := range logs {
+		if log.ComputeHash() != log.Hash {
+			t.Errorf("record %d no longer matches its hash after a round trip", log.Sequence)
+		}
+	}
+
+	if err := db.Model(&entity.AuditLog{}).Where("sequence = ?", 1).Update("action", "edited").Error; err == nil {
+		t.Error("updating an audit record succeeded")
+	}
+	if err := db.Where("sequence = ?", 1).Delete(&entity.AuditLog{}).Error; err == nil {
+		t.Error("deleting an audit record succeeded")
+	}
+}
+
+func TestDeletedGroupNameCanBeReused(t *testing.T) {
+	db := openDB(t)
+	repo := repository.NewGroupRepository(db)
+	organization := &entity.Organization{Name: "Acme", Slug: "acme"}
+	if err := db.Create(organization).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	group := &entity.Group{OrganizationID: organization.ID, Name: "Engineering"}
+	if err := repo.CreateGroup(context.Background(), group); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteGroup(context.Background(), group); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateGroup(context.Background(), &entity.Group{OrganizationID: organization.ID, Name: "Engineering"}); err != nil {
+		t.Errorf("recreating a deleted group's name failed: %v", err)
+	}
+}
+
+func TestDeleteRoleHeldByGroupAndMembership(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	roles := repository.NewRoleRepository(db)
+	role := &entity.Role{Name: "reviewer"}
+	organization := &entity.Organization{Name: "Acme", Slug: "acme"}
+	user := &entity.User{Name: "Alice", Email: "alice@example.com", Password: "secret"}
+	for _, record := range []interface{}{role, organization, user} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	membership := &entity.Membership{OrganizationID: organization.ID, UserID: user.ID, Roles: []*entity.Role{role}}
+	if err := db.Create(membership).Error; err != nil {
+		t.Fatal(err)
+	}
+	groups := repository.NewGroupRepository(db)
+	group := &entity.Group{OrganizationID: organization.ID, Name: "Reviewers"}
+	if err := groups.CreateGroup(ctx, group); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.AttachGroupRole(ctx, group, role); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := roles.DeleteRole(ctx, role); err != nil {
+		t.Fatalf("DeleteRole() error = %v", err)
+	}
+	for _, table := range []string{"membership_roles", "group_roles"} {
+		var count int64
+		if err := db.Table(table).Where("role_id = ?", role.ID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%s still holds the deleted role %d times", table, count)
+		}
+	}
+}
+
+func TestRepositoriesStopAtDeadline(t *testing.T) {
+	repo := repository.NewUserRepository(openDB(t))
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	_, err := repo.FindByEmail(ctx, "budi@example.com")
+	if apperror.From(err).Code != apperror.Timeout {
+		t.Errorf("FindByEmail past the deadline: %v, want a timeout", err)
+	}
+}
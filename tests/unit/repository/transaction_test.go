@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-backend/entity"
+	"golang-backend/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTransactionSpansRepositories(t *testing.T) {
+	db := openDB(t)
+	users := repository.NewUserRepository(db)
+	roles := repository.NewRoleRepository(db)
+	transactions := repository.NewTransactionManager(db)
+	ctx := context.Background()
+
+	role := &entity.Role{Name: "member"}
+	if err := roles.CreateRole(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("mail queue is down")
+	err := transactions.Transaction(ctx, func(ctx context.Context) error {
+		user := &entity.User{Name: "Budi", Email: "budi@example.com", Password: "x"}
+		if err := users.Create(ctx, user); err != nil {
+			return err
+		}
+		if err := roles.AttachRole(ctx, &entity.UserRole{UserID: user.ID, RoleID: role.ID}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Transaction() error = %v, want %v", err, failed)
+	}
+
+	if exists, _ := users.EmailExists(ctx, "budi@example.com"); exists {
+		t.Error("user created in a rolled back transaction was kept")
+	}
+	if holders, _ := roles.CountRoleHolders(ctx, role.ID, time.Now()); holders != 0 {
+		t.Errorf("role assignment of a rolled back transaction was kept: %d holders", holders)
+	}
+}
+
+func TestNestedTransactionRollsBackToSavepoint(t *testing.T) {
+	db := openDB(t)
+	users := repository.NewUserRepository(db)
+	transactions := repository.NewTransactionManager(db)
+	ctx := context.Background()
+
+	err := transactions.Transaction(ctx, func(ctx context.Context) error {
+		if err := users.Create(ctx, &entity.User{Name: "Outer", Email: "outer@example.com", Password: "x"}); err != nil {
+			return err
+		}
+		inner := transactions.Transaction(ctx, func(ctx context.Context) error {
+			if err := users.Create(ctx, &entity.User{Name: "Inner", Email: "inner@example.com", Password: "x"}); err != nil {
+				return err
+			}
+			return errors.New("inner step failed")
+		})
+		if inner == nil {
+			t.Error("inner transaction error was lost")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, _ := users.EmailExists(ctx, "outer@example.com"); !exists {
+		t.Error("outer transaction did not commit")
+	}
+	if exists, _ := users.EmailExists(ctx, "inner@example.com"); exists {
+		t.Error("inner transaction was not rolled back to its savepoint")
+	}
+}
+
+func TestTransactionRetriesSerializationFailures(t *testing.T) {
+	transactions := repository.NewTransactionManager(openDB(t))
+
+	attempts := 0
+	err := transactions.Transaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Transaction() = %v after %d attempts, want success on the second", err, attempts)
+	}
+
+	attempts = 0
+	transactions.Transaction(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return errors.New("not a conflict")
+	})
+	if attempts != 1 {
+		t.Errorf("other errors were retried: %d attempts", attempts)
+	}
+}
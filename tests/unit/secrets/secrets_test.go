@@ -0,0 +1,78 @@
+package secrets_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang-backend/secrets"
+)
+
+func newKey(t *testing.T) []byte {
+	t.Helper()
+	encoded, err := secrets.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := secrets.ParseKey(encoded)
+	if err != nil {
+		t.Fatalf("ParseKey(GenerateKey()) error = %v", err)
+	}
+	return key
+}
+
+func TestWriteAndReadFile(t *testing.T) {
+	key := newKey(t)
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+
+	if err := secrets.WriteFile(path, key, []byte("# production\nDB_PASSWORD: s3cret\nJWT_SECRET: \"123\"\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	values, err := secrets.ReadFile(path, key)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if values["DB_PASSWORD"] != "s3cret" || values["JWT_SECRET"] != "123" {
+		t.Fatalf("values = %v", values)
+	}
+}
+
+func TestOpenRejectsWrongKeyAndTampering(t *testing.T) {
+	key := newKey(t)
+	sealed, err := secrets.Seal(key, []byte("DB_PASSWORD: s3cret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := secrets.Open(newKey(t), sealed); !errors.Is(err, secrets.ErrDecrypt) {
+		t.Errorf("Open() with another key error = %v, want ErrDecrypt", err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-5] ^= 'A' ^ 'B'
+	if _, err := secrets.Open(key, tampered); !errors.Is(err, secrets.ErrDecrypt) {
+		t.Errorf("Open() of a modified file error = %v, want ErrDecrypt", err)
+	}
+
+	if _, err := secrets.Open(key, []byte("DB_PASSWORD: s3cret\n")); err == nil {
+		t.Error("Open() accepted a plaintext file")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	for _, encoded := range []string{"", "not base64!", "c2hvcnQ="} {
+		if _, err := secrets.ParseKey(encoded); !errors.Is(err, secrets.ErrInvalidKey) {
+			t.Errorf("ParseKey(%q) error = %v, want ErrInvalidKey", encoded, err)
+		}
+	}
+}
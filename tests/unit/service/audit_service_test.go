@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang-backend/entity"
+	"golang-backend/reqctx"
+	"golang-backend/service"
+	"golang-backend/utils"
+)
+
+// fakeAuditLogRepository chains records the same way the Postgres repository does
+type fakeAuditLogRepository struct {
+	logs []entity.AuditLog
+}
+
+func (f *fakeAuditLogRepository) Append(_ context.Context, log *entity.AuditLog) error {
+	var last entity.AuditLog
+	if len(f.logs) > 0 {
+		last = f.logs[len(f.logs)-1]
+	}
+	log.Sequence = last.Sequence + 1
+	log.PrevHash = last.Hash
+	log.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	log.Hash = log.ComputeHash()
+	f.logs = append(f.logs, *log)
+	return nil
+}
+
+func (f *fakeAuditLogRepository) Paginate(_ context.Context, filters map[string]interface{}, page, perPage int) (*utils.PaginationResult, error) {
+	return &utils.PaginationResult{Items: f.logs, Pagination: utils.CalculatePagination(int64(len(f.logs)), page, perPage)}, nil
+}
+
+func (f *fakeAuditLogRepository) FindAfter(_ context.Context, sequence int64, limit int) ([]entity.AuditLog, error) {
+	var logs []entity.AuditLog
+	for _, log := range f.logs {
+		if log.Sequence > sequence && len(logs) < limit {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func TestAuditService_RecordsChanges(t *testing.T) {
+	repo := &fakeAuditLogRepository{}
+	svc := service.NewAuditService(repo)
+
+	ctx := reqctx.WithRequest(context.Background(), reqctx.Request{ID: "req-1", IP: "10.0.0.1"})
+	ctx = reqctx.WithPrincipal(ctx, &entity.User{Base: entity.Base{ID: "admin-1"}})
+	svc.Record(ctx, service.AuditEntry{
+		Action:     entity.AuditRoleUpdated,
+		TargetType: "role",
+		TargetID:   "role-1",
+		Before:     map[string]interface{}{"name": "editor", "parents": []string{"user"}},
+		After:      map[string]interface{}{"name": "writer", "parents": []string{"user"}},
+	})
+
+	if len(repo.logs) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(repo.logs))
+	}
+	log := repo.logs[0]
+	if log.ActorID == nil || *log.ActorID != "admin-1" || log.RequestID != "req-1" || log.IP != "10.0.0.1" {
+		t.Errorf("Request details were not recorded: %+v", log)
+	}
+
+	var changes map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(log.Changes), &changes); err != nil {
+		t.Fatalf("Changes are not valid JSON: %v", err)
+	}
+	if len(changes) != 1 || changes["name"]["before"] != "editor" || changes["name"]["after"] != "writer" {
+		t.Errorf("Expected only the name change, got %s", log.Changes)
+	}
+}
+
+func TestAuditService_VerifyChain(t *testing.T) {
+	repo := &fakeAuditLogRepository{}
+	svc := service.NewAuditService(repo)
+	for _, action := range []string{entity.AuditRoleCreated, entity.AuditUserRoleAssigned, entity.AuditRoleDeleted} {
+		svc.Record(context.Background(), service.AuditEntry{Action: action, TargetType: "role", TargetID: "role-1"})
+	}
+
+	report, err := svc.VerifyChain(context.Background())
+	if err != nil || !report.Valid || report.Checked != 3 {
+		t.Fatalf("Expected an intact chain of 3, got %+v (%v)", report, err)
+	}
+
+	// Rewriting history breaks the record's own hash
+	repo.logs[1].Action = entity.AuditUserRoleRevoked
+	report, _ = svc.VerifyChain(context.Background())
+	if report.Valid || report.BrokenAt == nil || *report.BrokenAt != 2 {
+		t.Errorf("Expected the chain to break at record 2, got %+v", report)
+	}
+
+	// Deleting a record leaves a gap in the sequence
+	repo.logs[1].Action = entity.AuditUserRoleAssigned
+	repo.logs = append(repo.logs[:1], repo.logs[2:]...)
+	report, _ = svc.VerifyChain(context.Background())
+	if report.Valid || report.BrokenAt == nil || *report.BrokenAt != 3 {
+		t.Errorf("Expected the chain to break at record 3, got %+v", report)
+	}
+}
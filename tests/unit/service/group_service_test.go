@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang-backend/entity"
+	"golang-backend/repository"
+	"golang-backend/service"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeGroupRepository keeps the groups of one organization in memory.
+type fakeGroupRepository struct {
+	groups map[string]*entity.Group
+}
+
+func (f *fakeGroupRepository) PaginateGroups(context.Context, string, map[string]interface{}, int, int) (*utils.PaginationResult, error) {
+	return &utils.PaginationResult{}, nil
+}
+
+func (f *fakeGroupRepository) FindGroupByID(_ context.Context, organizationID, id string) (*entity.Group, error) {
+	if group, ok := f.groups[id]; ok && group.OrganizationID == organizationID {
+		return group, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeGroupRepository) FindGroupGraph(context.Context, []string) ([]*entity.Group, error) {
+	return nil, nil
+}
+
+func (f *fakeGroupRepository) CreateGroup(context.Context, *entity.Group) error { return nil }
+
+func (f *fakeGroupRepository) UpdateGroup(context.Context, *entity.Group) error { return nil }
+
+func (f *fakeGroupRepository) DeleteGroup(_ context.Context, group *entity.Group) error {
+	delete(f.groups, group.ID)
+	return nil
+}
+
+func (f *fakeGroupRepository) AttachGroupRole(context.Context, *entity.Group, *entity.Role) error {
+	return nil
+}
+
+func (f *fakeGroupRepository) DetachGroupRole(context.Context, *entity.Group, *entity.Role) error {
+	return nil
+}
+
+func (f *fakeGroupRepository) PaginateGroupMembers(context.Context, string, int, int) (*utils.PaginationResult, error) {
+	return &utils.PaginationResult{}, nil
+}
+
+func (f *fakeGroupRepository) AddGroupMember(context.Context, *entity.GroupMember) error { return nil }
+
+func (f *fakeGroupRepository) RemoveGroupMember(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+// fakeOrganizationRepository knows one organization without members; the
+// embedded interface panics on calls the tests do not expect.
+type fakeOrganizationRepository struct {
+	repository.OrganizationRepository
+	organization *entity.Organization
+}
+
+func (f *fakeOrganizationRepository) FindOrganizationByID(_ context.Context, id string) (*entity.Organization, error) {
+	if f.organization.ID == id {
+		return f.organization, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeOrganizationRepository) FindMembership(context.Context, string, string) (*entity.Membership, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeUserRepository finds users by email only.
+type fakeUserRepository struct {
+	repository.UserRepository
+	users []*entity.User
+}
+
+func (f *fakeUserRepository) FindByEmail(_ context.Context, email string) (*entity.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// tenantRoles seeds the roles an organization may or may not grant: a
+// tenant-only role, admin, a child of admin and a role holding "*:*".
+func tenantRoles() *fakeRoleRepository {
+	repo := newFakeRoleRepository()
+	repo.addRole("reviewer").Permissions = []*entity.Permission{repo.addPermission("groups:read"), repo.addPermission("members:read")}
+	admin := repo.addRole(entity.RoleAdmin)
+	repo.addRole("deputy").Parents = []*entity.Role{admin}
+	repo.addRole("root").Permissions = []*entity.Permission{repo.addPermission(entity.PermissionAll)}
+	repo.addRole("assigner").Permissions = []*entity.Permission{repo.addPermission("roles:assign")}
+	return repo
+}
+
+func TestGroupService_GrantGroupRoleRefusesGlobalRoles(t *testing.T) {
+	groups := &fakeGroupRepository{groups: map[string]*entity.Group{
+		"grp-1": {Base: entity.Base{ID: "grp-1"}, OrganizationID: "org-1"},
+	}}
+	svc := service.NewGroupService(groups, nil, tenantRoles(), fakeTransactions{}, nil)
+
+	for _, roleName := range []string{entity.RoleAdmin, "deputy", "root", "assigner"} {
+		if _, err := svc.GrantGroupRole(context.Background(), "org-1", "grp-1", roleName); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("Expected validation error granting %s to a group, got %v", roleName, err)
+		}
+	}
+
+	group, err := svc.GrantGroupRole(context.Background(), "org-1", "grp-1", "reviewer")
+	if err != nil {
+		t.Fatalf("Failed to grant a tenant role: %v", err)
+	}
+	if len(group.Roles) != 1 || group.Roles[0] != "reviewer" {
+		t.Errorf("Expected the group to hold reviewer, got %v", group.Roles)
+	}
+}
+
+func TestOrganizationService_InviteMemberRefusesGlobalRoles(t *testing.T) {
+	organizations := &fakeOrganizationRepository{organization: &entity.Organization{Base: entity.Base{ID: "org-1"}, Name: "Acme"}}
+	users := &fakeUserRepository{users: []*entity.User{{Base: entity.Base{ID: "u2"}, Email: "bob@example.com"}}}
+	svc := service.NewOrganizationService(organizations, users, tenantRoles(), fakeTransactions{}, nil, nil, nil)
+
+	for _, roleName := range []string{entity.RoleAdmin, "deputy", "root", "assigner"} {
+		if _, err := svc.InviteMember(context.Background(), "org-1", "u1", "bob@example.com", roleName); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("Expected validation error inviting as %s, got %v", roleName, err)
+		}
+	}
+}
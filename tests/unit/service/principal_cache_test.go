@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-backend/cache"
+	"golang-backend/entity"
+	"golang-backend/events"
+	"golang-backend/service"
+)
+
+func TestPrincipalCache_InvalidatedByEvents(t *testing.T) {
+	repo := newFakeRoleRepository()
+	editor := repo.addRole("editor")
+	editor.Permissions = []*entity.Permission{repo.addPermission("posts:update")}
+	user := repo.addUser("u1")
+	user.Password = "hashed"
+
+	bus := events.NewBus()
+	rbac := service.NewRBACService(repo, fakeTransactions{}, bus, nil)
+	principals := service.NewPrincipalCache(rbac, cache.NewMemoryStore(10), time.Minute, bus)
+
+	for i := 0; i < 3; i++ {
+		principal, err := principals.LoadPrincipal(context.Background(), "u1")
+		if err != nil {
+			t.Fatalf("Failed to load principal: %v", err)
+		}
+		if principal.Password != "" {
+			t.Error("Cached principal should not carry the password hash")
+		}
+	}
+	if repo.principalLoads != 1 {
+		t.Errorf("Expected one database load for repeated requests, got %d", repo.principalLoads)
+	}
+
+	if err := rbac.AssignRoleToUser(context.Background(), "u1", editor.Name, service.RoleGrant{}); err != nil {
+		t.Fatalf("Failed to assign role: %v", err)
+	}
+	principal, _ := principals.LoadPrincipal(context.Background(), "u1")
+	if !principal.HasPermission("posts:update") {
+		t.Error("Role assignment should invalidate the cached principal")
+	}
+
+	if _, err := rbac.SyncRolePermissions(context.Background(), editor.ID, nil); err != nil {
+		t.Fatalf("Failed to sync role permissions: %v", err)
+	}
+	principal, _ = principals.LoadPrincipal(context.Background(), "u1")
+	if principal.HasPermission("posts:update") {
+		t.Error("Role permission change should invalidate every cached principal")
+	}
+	if repo.principalLoads != 3 {
+		t.Errorf("Expected a reload after each change, got %d loads", repo.principalLoads)
+	}
+}
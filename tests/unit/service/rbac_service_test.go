@@ -0,0 +1,668 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-backend/entity"
+	"golang-backend/repository"
+	"golang-backend/service"
+	"golang-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeRoleRepository keeps roles, permissions and user assignments in memory.
+type fakeRoleRepository struct {
+	roles       map[string]*entity.Role
+	permissions map[string]*entity.Permission
+	users       map[string]*entity.User
+	assignments map[string]*entity.UserRole
+	groups      map[string]*entity.Group
+	userGroups  map[string][]string
+
+	principalLoads int
+}
+
+func newFakeRoleRepository() *fakeRoleRepository {
+	return &fakeRoleRepository{
+		roles:       map[string]*entity.Role{},
+		permissions: map[string]*entity.Permission{},
+		users:       map[string]*entity.User{},
+		assignments: map[string]*entity.UserRole{},
+		groups:      map[string]*entity.Group{},
+		userGroups:  map[string][]string{},
+	}
+}
+
+func (f *fakeRoleRepository) addRole(name string) *entity.Role {
+	role := &entity.Role{Base: entity.Base{ID: "role-" + name}, Name: name}
+	f.roles[role.ID] = role
+	return role
+}
+
+func (f *fakeRoleRepository) addPermission(name string) *entity.Permission {
+	permission := &entity.Permission{Base: entity.Base{ID: "perm-" + name}, Name: name}
+	f.permissions[permission.ID] = permission
+	return permission
+}
+
+func (f *fakeRoleRepository) addUser(id string, roles ...*entity.Role) *entity.User {
+	user := &entity.User{Base: entity.Base{ID: id}, Roles: roles}
+	f.users[id] = user
+	return user
+}
+
+// fakeTransactions runs the unit of work without a database.
+type fakeTransactions struct{}
+
+func (fakeTransactions) Transaction(ctx context.Context, fn func(ctx context.Context) error, _ ...*sql.TxOptions) error {
+	return fn(ctx)
+}
+
+func (f *fakeRoleRepository) PaginateRoles(context.Context, map[string]interface{}, int, int) (*utils.PaginationResult, error) {
+	return &utils.PaginationResult{}, nil
+}
+
+func (f *fakeRoleRepository) FindRoleByID(_ context.Context, id string) (*entity.Role, error) {
+	if role, ok := f.roles[id]; ok {
+		return role, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRoleRepository) FindRoleByName(_ context.Context, name string) (*entity.Role, error) {
+	for _, role := range f.roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRoleRepository) LockRole(context.Context, *entity.Role) error { return nil }
+
+func (f *fakeRoleRepository) CreateRole(_ context.Context, role *entity.Role) error {
+	role.ID = "role-" + role.Name
+	f.roles[role.ID] = role
+	return nil
+}
+
+func (f *fakeRoleRepository) UpdateRole(context.Context, *entity.Role) error { return nil }
+
+func (f *fakeRoleRepository) DeleteRole(_ context.Context, role *entity.Role) error {
+	delete(f.roles, role.ID)
+	return nil
+}
+
+func (f *fakeRoleRepository) AttachPermission(_ context.Context, role *entity.Role, permission *entity.Permission) error {
+	role.Permissions = append(role.Permissions, permission)
+	return nil
+}
+
+func (f *fakeRoleRepository) DetachPermission(_ context.Context, role *entity.Role, permission *entity.Permission) error {
+	kept := role.Permissions[:0]
+	for _, p := range role.Permissions {
+		if p.ID != permission.ID {
+			kept = append(kept, p)
+		}
+	}
+	role.Permissions = kept
+	return nil
+}
+
+func (f *fakeRoleRepository) ReplacePermissions(_ context.Context, role *entity.Role, permissions []*entity.Permission) error {
+	role.Permissions = permissions
+	return nil
+}
+
+func (f *fakeRoleRepository) FindRoleGraph(context.Context) ([]*entity.Role, error) {
+	roles := make([]*entity.Role, 0, len(f.roles))
+	for _, role := range f.roles {
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (f *fakeRoleRepository) AttachParent(context.Context, *entity.Role, *entity.Role) error {
+	return nil
+}
+
+func (f *fakeRoleRepository) DetachParent(_ context.Context, role, parent *entity.Role) error {
+	kept := role.Parents[:0]
+	for _, p := range role.Parents {
+		if p.ID != parent.ID {
+			kept = append(kept, p)
+		}
+	}
+	role.Parents = kept
+	return nil
+}
+
+func (f *fakeRoleRepository) PaginatePermissions(context.Context, map[string]interface{}, int, int) (*utils.PaginationResult, error) {
+	return &utils.PaginationResult{}, nil
+}
+
+func (f *fakeRoleRepository) FindPermissionByID(_ context.Context, id string) (*entity.Permission, error) {
+	if permission, ok := f.permissions[id]; ok {
+		return permission, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRoleRepository) FindPermissionByName(_ context.Context, name string) (*entity.Permission, error) {
+	for _, permission := range f.permissions {
+		if permission.Name == name {
+			return permission, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRoleRepository) FindPermissionsByNames(_ context.Context, names []string) ([]*entity.Permission, error) {
+	var found []*entity.Permission
+	for _, name := range names {
+		if permission, err := f.FindPermissionByName(context.Background(), name); err == nil {
+			found = append(found, permission)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeRoleRepository) FindAllPermissions(context.Context) ([]*entity.Permission, error) {
+	permissions := make([]*entity.Permission, 0, len(f.permissions))
+	for _, permission := range f.permissions {
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
+
+func (f *fakeRoleRepository) CreatePermission(_ context.Context, permission *entity.Permission) error {
+	permission.ID = "perm-" + permission.Name
+	f.permissions[permission.ID] = permission
+	return nil
+}
+
+func (f *fakeRoleRepository) UpdatePermission(context.Context, *entity.Permission) error { return nil }
+
+func (f *fakeRoleRepository) DeletePermission(_ context.Context, permission *entity.Permission) error {
+	delete(f.permissions, permission.ID)
+	return nil
+}
+
+func (f *fakeRoleRepository) FindUserWithRoles(_ context.Context, userID string) (*entity.User, error) {
+	if user, ok := f.users[userID]; ok {
+		return user, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRoleRepository) FindPrincipal(_ context.Context, userID string, at time.Time) (*entity.User, error) {
+	f.principalLoads++
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	principal := *user
+	principal.Roles = nil
+	for _, role := range user.Roles {
+		if f.activeAt(userID, role.ID, at) {
+			principal.Roles = append(principal.Roles, role)
+		}
+	}
+	return &principal, nil
+}
+
+func (f *fakeRoleRepository) FindUserGroupIDs(_ context.Context, userID string) ([]string, error) {
+	return f.userGroups[userID], nil
+}
+
+// FindGroupGraph returns unlinked copies, like rows fresh from the database
+func (f *fakeRoleRepository) FindGroupGraph(_ context.Context, organizationIDs []string) ([]*entity.Group, error) {
+	var groups []*entity.Group
+	for _, group := range f.groups {
+		for _, id := range organizationIDs {
+			if group.OrganizationID == id {
+				copied := *group
+				copied.Parent = nil
+				copied.Roles = nil
+				for _, role := range group.Roles {
+					copied.Roles = append(copied.Roles, &entity.Role{Base: entity.Base{ID: role.ID}, Name: role.Name})
+				}
+				groups = append(groups, &copied)
+			}
+		}
+	}
+	return groups, nil
+}
+
+func (f *fakeRoleRepository) AttachRole(_ context.Context, assignment *entity.UserRole) error {
+	f.users[assignment.UserID].AssignRole(f.roles[assignment.RoleID])
+	f.assignments[assignment.UserID+"/"+assignment.RoleID] = assignment
+	return nil
+}
+
+func (f *fakeRoleRepository) DetachRole(_ context.Context, user *entity.User, role *entity.Role) error {
+	kept := user.Roles[:0]
+	for _, r := range user.Roles {
+		if r.ID != role.ID {
+			kept = append(kept, r)
+		}
+	}
+	user.Roles = kept
+	delete(f.assignments, user.ID+"/"+role.ID)
+	return nil
+}
+
+func (f *fakeRoleRepository) CountRoleHolders(_ context.Context, roleID string, at time.Time) (int64, error) {
+	var holders int64
+	for _, user := range f.users {
+		for _, role := range user.Roles {
+			if role.ID == roleID && f.activeAt(user.ID, role.ID, at) {
+				holders++
+			}
+		}
+	}
+	return holders, nil
+}
+
+func (f *fakeRoleRepository) CountPermanentRoleHolders(_ context.Context, roleID, exceptUserID string, at time.Time) (int64, error) {
+	var holders int64
+	for _, user := range f.users {
+		assignment, timed := f.assignments[user.ID+"/"+roleID]
+		if user.ID == exceptUserID || !user.HasRole(f.roles[roleID].Name) || !f.activeAt(user.ID, roleID, at) {
+			continue
+		}
+		if !timed || assignment.ExpiresAt == nil {
+			holders++
+		}
+	}
+	return holders, nil
+}
+
+func (f *fakeRoleRepository) DeleteExpiredRoleAssignments(_ context.Context, at time.Time) (int64, error) {
+	var removed int64
+	for _, assignment := range f.assignments {
+		if assignment.ExpiresAt != nil && !at.Before(*assignment.ExpiresAt) {
+			user := f.users[assignment.UserID]
+			_ = f.DetachRole(context.Background(), user, f.roles[assignment.RoleID])
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (f *fakeRoleRepository) FindExpiringRoleAssignments(context.Context, time.Time, time.Time) ([]repository.ExpiringRoleAssignment, error) {
+	return nil, nil
+}
+
+func (f *fakeRoleRepository) MarkExpiryNotified(context.Context, string, string, time.Time) error {
+	return nil
+}
+
+// activeAt treats assignments without a recorded window as permanent.
+func (f *fakeRoleRepository) activeAt(userID, roleID string, at time.Time) bool {
+	assignment, ok := f.assignments[userID+"/"+roleID]
+	return !ok || assignment.ActiveAt(at)
+}
+
+func (f *fakeRoleRepository) SaveUserPermission(_ context.Context, userPermission *entity.UserPermission) error {
+	user := f.users[userPermission.UserID]
+	for _, existing := range user.UserPermissions {
+		if existing.PermissionID == userPermission.PermissionID {
+			existing.Effect = userPermission.Effect
+			return nil
+		}
+	}
+	userPermission.Permission = f.permissions[userPermission.PermissionID]
+	user.UserPermissions = append(user.UserPermissions, userPermission)
+	return nil
+}
+
+func (f *fakeRoleRepository) DeleteUserPermission(_ context.Context, userID, permissionID string) (bool, error) {
+	user, ok := f.users[userID]
+	if !ok {
+		return false, nil
+	}
+	for i, existing := range user.UserPermissions {
+		if existing.PermissionID == permissionID {
+			user.UserPermissions = append(user.UserPermissions[:i], user.UserPermissions[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRBACService_CreateRole(t *testing.T) {
+	repo := newFakeRoleRepository()
+	repo.addRole("editor")
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	if _, err := svc.CreateRole(context.Background(), "editor"); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict for duplicate role, got %v", err)
+	}
+
+	if _, err := svc.CreateRole(context.Background(), "   "); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("Expected validation error for blank name, got %v", err)
+	}
+
+	role, err := svc.CreateRole(context.Background(), " viewer ")
+	if err != nil {
+		t.Fatalf("Failed to create role: %v", err)
+	}
+	if role.Name != "viewer" {
+		t.Errorf("Expected trimmed name viewer, got %q", role.Name)
+	}
+}
+
+func TestRBACService_AdminRoleProtection(t *testing.T) {
+	repo := newFakeRoleRepository()
+	admin := repo.addRole(entity.RoleAdmin)
+	repo.addUser("u1", admin)
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	if err := svc.DeleteRole(context.Background(), admin.ID); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict when deleting admin role, got %v", err)
+	}
+
+	if _, err := svc.UpdateRole(context.Background(), admin.ID, "root"); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict when renaming admin role, got %v", err)
+	}
+
+	if err := svc.RevokeRoleFromUser(context.Background(), "u1", entity.RoleAdmin); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict when revoking the last admin, got %v", err)
+	}
+
+	repo.addUser("u2", admin)
+	if err := svc.RevokeRoleFromUser(context.Background(), "u1", entity.RoleAdmin); err != nil {
+		t.Errorf("Revoking admin with a second holder should succeed: %v", err)
+	}
+}
+
+func TestRBACService_TimeBoundAdminKeepsPermanentAdmin(t *testing.T) {
+	repo := newFakeRoleRepository()
+	admin := repo.addRole(entity.RoleAdmin)
+	repo.addUser("u1", admin)
+	repo.addUser("u2")
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	if err := svc.AssignRoleToUser(context.Background(), "u1", entity.RoleAdmin, service.RoleGrant{ExpiresAt: &tomorrow}); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict when the last permanent admin gets an expiry, got %v", err)
+	}
+
+	if err := svc.AssignRoleToUser(context.Background(), "u2", entity.RoleAdmin, service.RoleGrant{ExpiresAt: &tomorrow}); err != nil {
+		t.Fatalf("Granting a temporary admin next to a permanent one should succeed: %v", err)
+	}
+	if err := svc.RevokeRoleFromUser(context.Background(), "u1", entity.RoleAdmin); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict when revoking the last permanent admin beside a temporary one, got %v", err)
+	}
+	if err := svc.RevokeRoleFromUser(context.Background(), "u2", entity.RoleAdmin); err != nil {
+		t.Errorf("Revoking a temporary admin should succeed: %v", err)
+	}
+}
+
+func TestRBACService_AssignRoleToUser(t *testing.T) {
+	repo := newFakeRoleRepository()
+	editor := repo.addRole("editor")
+	repo.addUser("u1")
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	if err := svc.AssignRoleToUser(context.Background(), "missing", "editor", service.RoleGrant{}); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Expected not found for unknown user, got %v", err)
+	}
+
+	if err := svc.AssignRoleToUser(context.Background(), "u1", "ghost", service.RoleGrant{}); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Expected not found for unknown role, got %v", err)
+	}
+
+	if err := svc.AssignRoleToUser(context.Background(), "u1", editor.Name, service.RoleGrant{}); err != nil {
+		t.Fatalf("Failed to assign role: %v", err)
+	}
+
+	if err := svc.AssignRoleToUser(context.Background(), "u1", editor.Name, service.RoleGrant{}); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict for duplicate assignment, got %v", err)
+	}
+}
+
+func TestRBACService_TimeBoundRoleAssignment(t *testing.T) {
+	repo := newFakeRoleRepository()
+	editor := repo.addRole("editor")
+	editor.Permissions = []*entity.Permission{repo.addPermission("posts:update")}
+	repo.addUser("u1")
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	past := time.Now().Add(-time.Hour)
+	if err := svc.AssignRoleToUser(context.Background(), "u1", editor.Name, service.RoleGrant{ExpiresAt: &past}); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("Expected validation error for expiry in the past, got %v", err)
+	}
+
+	start := time.Now().Add(2 * time.Hour)
+	end := time.Now().Add(time.Hour)
+	if err := svc.AssignRoleToUser(context.Background(), "u1", editor.Name, service.RoleGrant{StartsAt: &start, ExpiresAt: &end}); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("Expected validation error for expiry before start, got %v", err)
+	}
+
+	if err := svc.AssignRoleToUser(context.Background(), "u1", editor.Name, service.RoleGrant{StartsAt: &start, GrantedBy: "admin"}); err != nil {
+		t.Fatalf("Failed to schedule role: %v", err)
+	}
+	principal, err := svc.LoadPrincipal(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("Failed to load principal: %v", err)
+	}
+	if principal.HasPermission("posts:update") {
+		t.Error("Scheduled role should not grant permissions before it starts")
+	}
+
+	if err := svc.AssignRoleToUser(context.Background(), "u1", editor.Name, service.RoleGrant{ExpiresAt: &end}); err != nil {
+		t.Fatalf("Re-assigning with a new window should succeed: %v", err)
+	}
+	principal, _ = svc.LoadPrincipal(context.Background(), "u1")
+	if !principal.HasPermission("posts:update") {
+		t.Error("Active time-bound role should grant its permissions")
+	}
+
+	// Simulate the window closing
+	repo.assignments["u1/"+editor.ID].ExpiresAt = &past
+	principal, _ = svc.LoadPrincipal(context.Background(), "u1")
+	if principal.HasPermission("posts:update") {
+		t.Error("Expired role should not grant permissions")
+	}
+
+	removed, err := svc.SweepExpiredRoles(context.Background())
+	if err != nil || removed != 1 {
+		t.Errorf("Expected one expired assignment swept, got %d (%v)", removed, err)
+	}
+	if repo.users["u1"].HasRole(editor.Name) {
+		t.Error("Swept role should be removed from the user")
+	}
+}
+
+func TestRBACService_SyncRolePermissions(t *testing.T) {
+	repo := newFakeRoleRepository()
+	role := repo.addRole("editor")
+	repo.addPermission("edit_post")
+	repo.addPermission("delete_post")
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	_, err := svc.SyncRolePermissions(context.Background(), role.ID, []string{"edit_post", "publish_post"})
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) || !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("Expected not found for unknown permission, got %v", err)
+	}
+	if missing, _ := serviceErr.Details.([]string); len(missing) != 1 || missing[0] != "publish_post" {
+		t.Errorf("Expected publish_post to be reported missing, got %v", serviceErr.Details)
+	}
+
+	updated, err := svc.SyncRolePermissions(context.Background(), role.ID, []string{"edit_post", "delete_post", "edit_post"})
+	if err != nil {
+		t.Fatalf("Failed to sync permissions: %v", err)
+	}
+	if len(updated.Permissions) != 2 {
+		t.Errorf("Expected 2 permissions after sync, got %d", len(updated.Permissions))
+	}
+}
+
+func TestRBACService_AddParentRole(t *testing.T) {
+	repo := newFakeRoleRepository()
+	user := repo.addRole("user")
+	manager := repo.addRole("manager")
+	director := repo.addRole("director")
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	if _, err := svc.AddParentRole(context.Background(), manager.ID, "manager"); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("Expected validation error for self inheritance, got %v", err)
+	}
+
+	if _, err := svc.AddParentRole(context.Background(), manager.ID, "user"); err != nil {
+		t.Fatalf("Failed to add parent: %v", err)
+	}
+	if _, err := svc.AddParentRole(context.Background(), director.ID, "manager"); err != nil {
+		t.Fatalf("Failed to add parent: %v", err)
+	}
+
+	if _, err := svc.AddParentRole(context.Background(), manager.ID, "user"); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict for duplicate parent, got %v", err)
+	}
+
+	// user -> director would close director -> manager -> user -> director
+	if _, err := svc.AddParentRole(context.Background(), user.ID, "director"); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("Expected conflict for cyclic hierarchy, got %v", err)
+	}
+}
+
+func TestRBACService_LoadPrincipalInheritsPermissions(t *testing.T) {
+	repo := newFakeRoleRepository()
+	user := repo.addRole("user")
+	manager := repo.addRole("manager")
+	user.Permissions = []*entity.Permission{repo.addPermission("view_reports")}
+	repo.addUser("u1", &entity.Role{Base: entity.Base{ID: manager.ID}, Name: manager.Name})
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	if _, err := svc.AddParentRole(context.Background(), manager.ID, "user"); err != nil {
+		t.Fatalf("Failed to add parent: %v", err)
+	}
+
+	principal, err := svc.LoadPrincipal(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("Failed to load principal: %v", err)
+	}
+
+	if !principal.HasPermission("view_reports") {
+		t.Error("Manager should inherit view_reports from user")
+	}
+	if principal.HasRole("user") {
+		t.Error("HasRole should only consider directly assigned roles")
+	}
+	if !principal.HasImpliedRole("user") {
+		t.Error("HasImpliedRole should consider inherited roles")
+	}
+
+	permissions, err := svc.GetEffectivePermissions(context.Background(), manager.ID)
+	if err != nil || len(permissions) != 1 {
+		t.Errorf("Expected 1 effective permission for manager, got %d (%v)", len(permissions), err)
+	}
+}
+
+func TestRBACService_LoadPrincipalGroupRoles(t *testing.T) {
+	repo := newFakeRoleRepository()
+	editor := repo.addRole("editor")
+	editor.Permissions = []*entity.Permission{repo.addPermission("posts:*")}
+	repo.addUser("u1").Memberships = []*entity.Membership{{OrganizationID: "org-1", Status: entity.MembershipActive}}
+
+	parentID := "grp-engineering"
+	repo.groups[parentID] = &entity.Group{Base: entity.Base{ID: parentID}, OrganizationID: "org-1", Roles: []*entity.Role{editor}}
+	repo.groups["grp-backend"] = &entity.Group{Base: entity.Base{ID: "grp-backend"}, OrganizationID: "org-1", ParentID: &parentID}
+	repo.groups["grp-other"] = &entity.Group{Base: entity.Base{ID: "grp-other"}, OrganizationID: "org-2", Roles: []*entity.Role{editor}}
+	repo.userGroups["u1"] = []string{"grp-backend", "grp-other"}
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	principal, err := svc.LoadPrincipal(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("Failed to load principal: %v", err)
+	}
+
+	if len(principal.Groups) != 1 {
+		t.Fatalf("Expected only the group of an active membership, got %d", len(principal.Groups))
+	}
+	if !principal.HasPermission("posts:update") {
+		t.Error("Subgroup member should get the roles of the parent group")
+	}
+
+	principal.ScopeGroups("org-2")
+	if principal.HasPermission("posts:update") {
+		t.Error("Group grants should not apply outside their organization")
+	}
+}
+
+func TestRBACService_UserPermissions(t *testing.T) {
+	repo := newFakeRoleRepository()
+	editor := repo.addRole("editor")
+	editor.Permissions = []*entity.Permission{repo.addPermission("posts:*")}
+	repo.addPermission("posts:delete")
+	repo.addPermission("reports:read")
+	repo.addUser("u1", editor)
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	if _, err := svc.GrantUserPermission(context.Background(), "u1", "reports:read", "maybe"); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("Expected validation error for unknown effect, got %v", err)
+	}
+
+	if _, err := svc.GrantUserPermission(context.Background(), "u1", "reports:read", ""); err != nil {
+		t.Fatalf("Failed to grant permission: %v", err)
+	}
+	if _, err := svc.GrantUserPermission(context.Background(), "u1", "posts:delete", entity.PermissionEffectDeny); err != nil {
+		t.Fatalf("Failed to deny permission: %v", err)
+	}
+
+	principal, err := svc.LoadPrincipal(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("Failed to load principal: %v", err)
+	}
+	if !principal.HasPermission("reports:read") {
+		t.Error("Direct allow should grant reports:read")
+	}
+	if principal.HasPermission("posts:delete") {
+		t.Error("Direct deny should override the role's posts:* grant")
+	}
+	if !principal.HasPermission("posts:update") {
+		t.Error("Deny should not block other actions covered by posts:*")
+	}
+
+	if err := svc.RevokeUserPermission(context.Background(), "u1", "posts:delete"); err != nil {
+		t.Fatalf("Failed to revoke permission: %v", err)
+	}
+	if err := svc.RevokeUserPermission(context.Background(), "u1", "posts:delete"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("Expected not found for missing entry, got %v", err)
+	}
+}
+
+func TestRBACService_SyncPermissions(t *testing.T) {
+	repo := newFakeRoleRepository()
+	repo.addPermission("roles:*")
+	repo.addPermission("users:read")
+	repo.addPermission("reports:read")
+	svc := service.NewRBACService(repo, fakeTransactions{}, nil, nil)
+
+	if _, err := svc.SyncPermissions(context.Background(), []string{"manage_users"}); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("Expected validation error for malformed permission, got %v", err)
+	}
+
+	report, err := svc.SyncPermissions(context.Background(), []string{"users:read", "roles:read", "routes:read", "roles:read"})
+	if err != nil {
+		t.Fatalf("Failed to sync permissions: %v", err)
+	}
+
+	if len(report.Created) != 2 || report.Created[0] != "roles:read" || report.Created[1] != "routes:read" {
+		t.Errorf("Expected roles:read and routes:read to be created, got %v", report.Created)
+	}
+	if len(report.Orphaned) != 1 || report.Orphaned[0] != "reports:read" {
+		t.Errorf("Expected only reports:read to be orphaned, got %v", report.Orphaned)
+	}
+	if _, err := repo.FindPermissionByName(context.Background(), "routes:read"); err != nil {
+		t.Errorf("Created permission should be stored: %v", err)
+	}
+}
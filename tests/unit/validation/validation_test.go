@@ -0,0 +1,88 @@
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang-backend/dto"
+	"golang-backend/i18n"
+	"golang-backend/validation"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+type fakeEmails map[string]bool
+
+func (f fakeEmails) EmailExists(_ context.Context, email string) (bool, error) {
+	if email == "broken@example.com" {
+		return false, errors.New("connection refused")
+	}
+	return f[email], nil
+}
+
+func register(t *testing.T) {
+	t.Helper()
+	if err := validation.Register(fakeEmails{"taken@example.com": true}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+}
+
+func TestFieldErrorsUseJSONNames(t *testing.T) {
+	register(t)
+
+	err := binding.Validator.ValidateStruct(&dto.UserRegisterRequest{
+		Email:    "not-an-email",
+		Password: "short",
+	})
+
+	got := validation.FieldErrors(err, i18n.English)
+	want := map[string][]string{
+		"name":     {"is required"},
+		"email":    {"must be a valid email"},
+		"password": {"must be at least 8 characters with upper and lower case letters and a number"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FieldErrors() = %v, want %v", got, want)
+	}
+}
+
+func TestFieldErrorsInIndonesian(t *testing.T) {
+	register(t)
+
+	err := binding.Validator.ValidateStruct(&dto.CreateGroupRequest{Name: strings.Repeat("a", 101)})
+
+	got := validation.FieldErrors(err, i18n.Indonesian)
+	if want := []string{"maksimal 100 karakter"}; !reflect.DeepEqual(got["name"], want) {
+		t.Fatalf("FieldErrors()[name] = %v, want %v", got["name"], want)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	register(t)
+
+	cases := []struct {
+		name  string
+		input interface{}
+		field string
+		valid bool
+	}{
+		{"strong password", &dto.UserRegisterRequest{Name: "A", Email: "new@example.com", Password: "Secret123"}, "password", true},
+		{"password without digit", &dto.UserRegisterRequest{Name: "A", Email: "new@example.com", Password: "SecretSecret"}, "password", false},
+		{"taken email", &dto.UserRegisterRequest{Name: "A", Email: "taken@example.com", Password: "Secret123"}, "email", false},
+		{"email lookup error lets the value through", &dto.UserRegisterRequest{Name: "A", Email: "broken@example.com", Password: "Secret123"}, "email", true},
+		{"ulid", &dto.AddGroupMemberRequest{UserID: "01HZY3W6Q6Z9J5R8K2M4N7P1TB"}, "user_id", true},
+		{"not a ulid", &dto.AddGroupMemberRequest{UserID: "42"}, "user_id", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields := validation.FieldErrors(binding.Validator.ValidateStruct(tc.input), i18n.English)
+			if _, failed := fields[tc.field]; failed == tc.valid {
+				t.Fatalf("field %q errors = %v, want valid %v", tc.field, fields[tc.field], tc.valid)
+			}
+		})
+	}
+}
@@ -1,41 +1,64 @@
 package utils
 
 import (
-	"fmt"
 	"golang-backend/config"
+	"golang-backend/i18n"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
-func SendVerificationEmail(toEmail, code string) error {
-	mailer := gomail.NewMessage()
-	mailer.SetHeader("From", config.AppConfig.SMTPUser)
-	mailer.SetHeader("To", toEmail)
-	mailer.SetHeader("Subject", "Email Verification Code")
-	mailer.SetBody("text/html", fmt.Sprintf("Your verification code is: <b>%s</b>", code))
+// Mailer sends the application's emails over SMTP. Emails are written in
+// English and translated to the recipient's locale; an empty locale uses
+// the default.
+type Mailer struct {
+	cfg config.SMTPConfig
+}
 
-	dialer := gomail.NewDialer(
-		config.AppConfig.SMTPHost,
-		config.AppConfig.SMTPPort,
-		config.AppConfig.SMTPUser,
-		config.AppConfig.SMTPPass,
+func NewMailer(cfg config.SMTPConfig) *Mailer {
+	return &Mailer{cfg: cfg}
+}
+
+func (m *Mailer) SendVerificationEmail(toEmail, code, locale string) error {
+	return m.send(toEmail,
+		i18n.T(locale, "Email Verification Code"),
+		i18n.T(locale, "Your verification code is: <b>%s</b>", code),
 	)
+}
 
-	return dialer.DialAndSend(mailer)
+func (m *Mailer) SendResetPasswordEmail(toEmail, code, locale string) error {
+	return m.send(toEmail,
+		i18n.T(locale, "Reset Password Code"),
+		i18n.T(locale, "Your reset password code is: <b>%s</b>", code),
+	)
+}
+
+func (m *Mailer) SendRoleExpiryEmail(toEmail, roleName string, expiresAt time.Time, locale string) error {
+	return m.send(toEmail,
+		i18n.T(locale, "Role Expiring Soon"),
+		i18n.T(locale, "Your <b>%s</b> role expires on %s.", roleName, expiresAt.Format(time.RFC1123)),
+	)
+}
+
+func (m *Mailer) SendOrganizationInviteEmail(toEmail, organizationName, locale string) error {
+	return m.send(toEmail,
+		i18n.T(locale, "Organization Invitation"),
+		i18n.T(locale, "You have been invited to join <b>%s</b>. Sign in to accept the invitation.", organizationName),
+	)
 }
 
-func SendResetPasswordEmail(toEmail, code string) error {
+func (m *Mailer) send(toEmail, subject, body string) error {
 	mailer := gomail.NewMessage()
-	mailer.SetHeader("From", config.AppConfig.SMTPUser)
+	mailer.SetHeader("From", m.cfg.Sender())
 	mailer.SetHeader("To", toEmail)
-	mailer.SetHeader("Subject", "Reset Password Code")
-	mailer.SetBody("text/html", fmt.Sprintf("Your reset password code is: <b>%s</b>", code))
+	mailer.SetHeader("Subject", subject)
+	mailer.SetBody("text/html", body)
 
 	dialer := gomail.NewDialer(
-		config.AppConfig.SMTPHost,
-		config.AppConfig.SMTPPort,
-		config.AppConfig.SMTPUser,
-		config.AppConfig.SMTPPass,
+		m.cfg.Host,
+		m.cfg.Port,
+		m.cfg.User,
+		m.cfg.Password,
 	)
 
 	return dialer.DialAndSend(mailer)
@@ -3,21 +3,26 @@ package utils
 import (
 	"net/http"
 
+	"golang-backend/i18n"
+
 	"github.com/gin-gonic/gin"
 )
 
 type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
+	Code    string      `json:"code,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Errors  interface{} `json:"errors,omitempty"`
 	Meta    interface{} `json:"meta,omitempty"`
 }
 
+// APIResponse writes the standard envelope. The message is written in
+// English and translated to the request's locale.
 func APIResponse(ctx *gin.Context, message string, statusCode int, data, errors, meta interface{}) {
 	jsonResponse := Response{
 		Success: statusCode >= 200 && statusCode < 300,
-		Message: message,
+		Message: translate(ctx, message),
 		Data:    data,
 		Errors:  errors,
 		Meta:    meta,
@@ -39,6 +44,22 @@ func ErrorResponse(ctx *gin.Context, message string, statusCode int, errors inte
 	APIResponse(ctx, message, statusCode, nil, errors, nil) // Meta usually nil for errors
 }
 
+// ErrorCodeResponse is ErrorResponse with a machine-readable error code.
+func ErrorCodeResponse(ctx *gin.Context, message, code string, statusCode int, errors interface{}) {
+	ctx.JSON(statusCode, Response{
+		Success: false,
+		Message: translate(ctx, message),
+		Code:    code,
+		Errors:  errors,
+	})
+}
+
 func PaginatedResponse(ctx *gin.Context, message string, data, meta interface{}) {
 	APIResponse(ctx, message, http.StatusOK, data, nil, meta)
 }
+
+func translate(ctx *gin.Context, message string) string {
+	locale := i18n.Locale(ctx)
+	ctx.Header("Content-Language", locale)
+	return i18n.T(locale, message)
+}
@@ -9,40 +9,74 @@ import (
 )
 
 func SeedRolesAndPermissions(db *gorm.DB) {
-	// Define permissions
+	// Define permissions (resource:action, "*" matches anything)
 	permissions := []string{
-		"manage_users",
-		"manage_roles",
-		"view_reports",
+		entity.PermissionAll,
+		"users:read",
+		"users:create",
+		"users:update",
+		"users:delete",
+		"roles:*",
+		"reports:read",
+		"organizations:*",
+		"members:*",
+		"members:read",
+		"groups:*",
+		"audit-logs:read",
+		"config:read",
 	}
 
-	var createdPermissions []*entity.Permission
+	seededPermissions := map[string]*entity.Permission{}
 	for _, permName := range permissions {
 		perm := &entity.Permission{Name: permName}
 		if err := db.FirstOrCreate(perm, entity.Permission{Name: permName}).Error; err != nil {
 			log.Printf("Failed to seed permission %s: %v", permName, err)
 		} else {
-			createdPermissions = append(createdPermissions, perm)
+			seededPermissions[permName] = perm
 		}
 	}
 
 	// Define Roles
-	roles := []string{"admin", "user", "manager"}
+	roles := []string{"admin", "user", "manager", entity.RoleOrganizationOwner, entity.RoleOrganizationMember}
+	seededRoles := map[string]*entity.Role{}
 
 	for _, roleName := range roles {
 		role := &entity.Role{Name: roleName}
 		if err := db.FirstOrCreate(role, entity.Role{Name: roleName}).Error; err != nil {
 			log.Printf("Failed to seed role %s: %v", roleName, err)
 		}
+		seededRoles[roleName] = role
 
-		// Assign permissions to admin
-		if roleName == "admin" {
-			if err := db.Model(role).Association("Permissions").Replace(createdPermissions); err != nil {
+		// Admin is a super-admin through the wildcard permission
+		if superAdmin, ok := seededPermissions[entity.PermissionAll]; ok && roleName == "admin" {
+			if err := db.Model(role).Association("Permissions").Replace(superAdmin); err != nil {
 				log.Printf("Failed to replace permissions for role %s: %v", roleName, err)
 			}
 		}
 	}
 
+	// Managers inherit everything a regular user can do
+	if err := db.Model(seededRoles["manager"]).Omit("Parents.*").Association("Parents").Replace(seededRoles["user"]); err != nil {
+		log.Printf("Failed to seed role hierarchy: %v", err)
+	}
+
+	// Organization roles, granted through memberships and only inside that organization
+	orgRolePermissions := map[string][]string{
+		entity.RoleOrganizationOwner:  {"organizations:*", "members:*", "groups:*", "users:read", "users:update"},
+		entity.RoleOrganizationMember: {"members:read"},
+	}
+	for roleName, permNames := range orgRolePermissions {
+		var perms []*entity.Permission
+		for _, permName := range permNames {
+			if perm, ok := seededPermissions[permName]; ok {
+				perms = append(perms, perm)
+			}
+		}
+		if err := db.Model(seededRoles[roleName]).Association("Permissions").Replace(perms); err != nil {
+			log.Printf("Failed to replace permissions for role %s: %v", roleName, err)
+		}
+	}
+
 	log.Println("✓ Roles and Permissions seeded successfully")
 }
 
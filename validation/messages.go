@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"golang-backend/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Rule messages in English, translated through the i18n catalogs. {param}
+// is replaced by the rule parameter, e.g. the 6 in min=6.
+var ruleMessages = map[string]string{
+	"required":        "is required",
+	"email":           "must be a valid email",
+	"oneof":           "must be one of: {param}",
+	"strong_password": "must be at least 8 characters with upper and lower case letters and a number",
+	"ulid":            "must be a valid ID",
+	"unique_email":    "is already registered",
+}
+
+// Length rules read differently for strings, lists and numbers.
+var lengthMessages = map[string]map[string]string{
+	"min": {
+		"string": "must be at least {param} characters",
+		"list":   "must contain at least {param} items",
+		"number": "must be at least {param}",
+	},
+	"max": {
+		"string": "must be at most {param} characters",
+		"list":   "must contain at most {param} items",
+		"number": "must be at most {param}",
+	},
+}
+
+// FieldErrors translates a binding error into messages per JSON field, e.g.
+// {"email": ["must be a valid email"]}. It returns nil when the error does
+// not point at a field, such as malformed JSON.
+func FieldErrors(err error, locale string) map[string][]string {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make(map[string][]string, len(validationErrs))
+		for _, fe := range validationErrs {
+			field := fieldPath(fe.Namespace())
+			fields[field] = append(fields[field], translate(fe, locale))
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return map[string][]string{typeErr.Field: {i18n.T(locale, "has an invalid type")}}
+	}
+
+	return nil
+}
+
+// fieldPath drops the struct name from the namespace:
+// "BatchCanRequest.checks[0].action" becomes "checks[0].action".
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func translate(fe validator.FieldError, locale string) string {
+	message, ok := ruleMessages[fe.Tag()]
+	if byKind, isLength := lengthMessages[fe.Tag()]; isLength {
+		message, ok = byKind[kindOf(fe.Kind())], true
+	}
+	if !ok {
+		return i18n.T(locale, "is invalid")
+	}
+	return strings.ReplaceAll(i18n.T(locale, message), "{param}", strings.ReplaceAll(fe.Param(), " ", ", "))
+}
+
+func kindOf(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "list"
+	}
+	return "number"
+}
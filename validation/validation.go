@@ -0,0 +1,105 @@
+// Package validation registers the custom binding rules and turns binding
+// errors into per-field messages keyed by JSON field name.
+package validation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/oklog/ulid/v2"
+)
+
+// MinPasswordLength is the shortest password strong_password accepts.
+const MinPasswordLength = 8
+
+// EmailChecker reports whether an email is already taken. It is satisfied
+// by repository.UserRepository.
+type EmailChecker interface {
+	EmailExists(ctx context.Context, email string) (bool, error)
+}
+
+// Register installs JSON field names and the custom rules on gin's validator:
+//
+//	strong_password  at least MinPasswordLength characters with upper and lower case letters and a digit
+//	ulid             a valid ULID, the format of every entity ID
+//	unique_email     no user is registered with the email yet
+func Register(emails EmailChecker) error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: gin is not using go-playground/validator")
+	}
+
+	engine.RegisterTagNameFunc(jsonName)
+
+	rules := map[string]validator.Func{
+		"strong_password": strongPassword,
+		"ulid":            validULID,
+		"unique_email":    uniqueEmail(emails),
+	}
+	for tag, rule := range rules {
+		if err := engine.RegisterValidation(tag, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonName names fields after their json tag, so errors use the names
+// clients send.
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+func strongPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len([]rune(password)) < MinPasswordLength {
+		return false
+	}
+
+	var upper, lower, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// validULID parses with the same library that generates the IDs, which also
+// rejects timestamps that overflow.
+func validULID(fl validator.FieldLevel) bool {
+	_, err := ulid.ParseStrict(fl.Field().String())
+	return err == nil
+}
+
+// uniqueEmail fails when the email is taken. A lookup error lets the value
+// through; the unique index still rejects duplicates on insert. gin binds
+// without the request context, so the lookup runs without its deadline.
+func uniqueEmail(emails EmailChecker) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		exists, err := emails.EmailExists(context.Background(), fl.Field().String())
+		if err != nil {
+			slog.Warn("Email uniqueness check failed", "error", err)
+			return true
+		}
+		return !exists
+	}
+}